@@ -0,0 +1,170 @@
+// Package audit records every use of a secretbox key in a signed log.
+//
+// Each entry is a line of JSON holding a sequence number, the time, the
+// operation, a fingerprint of the key, the SHA-256 hash of the box that was
+// sealed or opened, whether the operation succeeded, and an Ed25519 signature
+// of the rest of the entry by a separate audit signing key. The signatures
+// stop anyone without the signing key from forging or changing entries, and
+// the sequence numbers show when entries have been removed or reordered.
+//
+// Entries hash the box rather than the message, so the log does not help an
+// attacker guess the contents of low-entropy messages.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+	"github.com/kevinburke/nacl/sign"
+	"golang.org/x/crypto/ed25519"
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+var errInvalidInput = errors.New("audit: Could not decrypt invalid input")
+
+// An Entry is a record of one use of a key.
+type Entry struct {
+	Seq       uint64 `json:"seq"`
+	Time      int64  `json:"time"` // nanoseconds since the Unix epoch
+	Op        string `json:"op"`   // "seal" or "open"
+	Key       string `json:"key"`  // hex key fingerprint
+	BoxHash   string `json:"box"`  // hex SHA-256 of the box
+	OK        bool   `json:"ok"`
+	Signature []byte `json:"sig,omitempty"`
+}
+
+// Fingerprint returns a short hex identifier for key, the first 8 bytes of
+// the SHA-256 hash of a fixed label and the key. It identifies the key in the
+// log without revealing it.
+func Fingerprint(key nacl.Key) string {
+	h := sha256.New()
+	h.Write([]byte("nacl audit key fingerprint\x00"))
+	h.Write(key[:])
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// signedBytes returns the bytes covered by an entry's signature: the JSON
+// encoding of the entry without its signature.
+func signedBytes(e Entry) []byte {
+	e.Signature = nil
+	b, err := json.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// An AuditKey wraps a secretbox key, and writes an entry to a log every time
+// it is used. It is safe for concurrent use by multiple goroutines, provided
+// the log is only written to by the AuditKey.
+type AuditKey struct {
+	key         nacl.Key
+	fingerprint string
+	signingKey  sign.PrivateKey
+
+	mu  sync.Mutex
+	log io.Writer
+	seq uint64
+}
+
+// NewAuditKey returns an AuditKey that seals and opens with k, and writes
+// entries signed with signingKey to auditLog. signingKey should be used only
+// for the audit log, and should be kept away from anyone who can modify it.
+func NewAuditKey(k nacl.Key, auditLog io.Writer, signingKey sign.PrivateKey) *AuditKey {
+	return &AuditKey{
+		key:         k,
+		fingerprint: Fingerprint(k),
+		signingKey:  signingKey,
+		log:         auditLog,
+	}
+}
+
+func (a *AuditKey) record(op string, box []byte, ok bool) error {
+	hash := sha256.Sum256(box)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq++
+	e := Entry{
+		Seq:     a.seq,
+		Time:    now().UnixNano(),
+		Op:      op,
+		Key:     a.fingerprint,
+		BoxHash: hex.EncodeToString(hash[:]),
+		OK:      ok,
+	}
+	e.Signature = ed25519.Sign(ed25519.PrivateKey(a.signingKey), signedBytes(e))
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = a.log.Write(append(line, '\n'))
+	return err
+}
+
+// Seal calls secretbox.Seal with the wrapped key and logs the use. If the
+// entry cannot be written, Seal returns the error and no box, so no key use
+// goes unrecorded.
+func (a *AuditKey) Seal(out, message []byte, nonce nacl.Nonce) ([]byte, error) {
+	box := secretbox.Seal(out, message, nonce, a.key)
+	if err := a.record("seal", box[len(out):], true); err != nil {
+		return nil, err
+	}
+	return box, nil
+}
+
+// Open calls secretbox.Open with the wrapped key and logs the attempt,
+// whether or not it succeeds. If the entry cannot be written, Open returns
+// the error and no plaintext.
+func (a *AuditKey) Open(out, box []byte, nonce nacl.Nonce) ([]byte, error) {
+	opened, ok := secretbox.Open(out, box, nonce, a.key)
+	if err := a.record("open", box, ok); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidInput
+	}
+	return opened, nil
+}
+
+// VerifyLog reads a log written by an AuditKey from r, and checks that every
+// entry is signed by publicKey and that the sequence numbers start at 1 and
+// have no gaps. It returns the entries, or an error describing the first
+// problem found.
+//
+// Entries removed from the end of the log cannot be detected this way; keep
+// the latest sequence number somewhere the log's writer cannot modify it.
+func VerifyLog(r io.Reader, publicKey sign.PublicKey) ([]Entry, error) {
+	if len(publicKey) != sign.PublicKeySize {
+		return nil, errors.New("audit: bad public key length")
+	}
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("audit: line %d: %v", line, err)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(publicKey), signedBytes(e), e.Signature) {
+			return nil, fmt.Errorf("audit: line %d: invalid signature", line)
+		}
+		if e.Seq != uint64(line) {
+			return nil, fmt.Errorf("audit: line %d: got sequence number %d, want %d", line, e.Seq, line)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
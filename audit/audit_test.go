@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/sign"
+)
+
+func newAuditKey(t *testing.T, w *bytes.Buffer) (*AuditKey, nacl.Key, sign.PublicKey) {
+	t.Helper()
+	pub, priv, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := nacl.NewKey()
+	return NewAuditKey(key, w, priv), key, pub
+}
+
+func TestAuditKey(t *testing.T) {
+	at := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	var log bytes.Buffer
+	a, key, pub := newAuditKey(t, &log)
+	nonce := nacl.NewNonce()
+	message := []byte("top secret")
+	box, err := a.Seal(nil, message, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := a.Open(nil, box, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+	if _, err := a.Open(nil, box, nacl.NewNonce()); err == nil {
+		t.Error("opened box with wrong nonce")
+	}
+	if bytes.Contains(log.Bytes(), message) {
+		t.Error("log contains the message")
+	}
+
+	entries, err := VerifyLog(bytes.NewReader(log.Bytes()), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	boxHash := sha256.Sum256(box)
+	for i, want := range []struct {
+		op string
+		ok bool
+	}{{"seal", true}, {"open", true}, {"open", false}} {
+		e := entries[i]
+		if e.Seq != uint64(i+1) || e.Op != want.op || e.OK != want.ok {
+			t.Errorf("entry %d: got seq %d, op %q, ok %t", i, e.Seq, e.Op, e.OK)
+		}
+		if e.Key != Fingerprint(key) {
+			t.Errorf("entry %d: got key %q, want %q", i, e.Key, Fingerprint(key))
+		}
+		if e.BoxHash != hex.EncodeToString(boxHash[:]) {
+			t.Errorf("entry %d: got box hash %s", i, e.BoxHash)
+		}
+		if e.Time != at.UnixNano() {
+			t.Errorf("entry %d: got time %d, want %d", i, e.Time, at.UnixNano())
+		}
+	}
+}
+
+func TestSealWithPrefix(t *testing.T) {
+	var log bytes.Buffer
+	a, _, pub := newAuditKey(t, &log)
+	nonce := nacl.NewNonce()
+	box, err := a.Seal([]byte("prefix"), []byte("message"), nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := VerifyLog(&log, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the box is hashed, not the caller's prefix.
+	hash := sha256.Sum256(box[len("prefix"):])
+	if entries[0].BoxHash != hex.EncodeToString(hash[:]) {
+		t.Errorf("box hash covers the output prefix")
+	}
+}
+
+func TestVerifyLogTampering(t *testing.T) {
+	var log bytes.Buffer
+	a, _, pub := newAuditKey(t, &log)
+	for i := 0; i < 3; i++ {
+		if _, err := a.Seal(nil, []byte("m"), nacl.NewNonce()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.SplitAfter(log.String(), "\n")[:3]
+
+	modified := strings.Replace(lines[1], `"op":"seal"`, `"op":"open"`, 1)
+	if _, err := VerifyLog(strings.NewReader(lines[0]+modified+lines[2]), pub); err == nil {
+		t.Error("verified log with a modified entry")
+	}
+	if _, err := VerifyLog(strings.NewReader(lines[0]+lines[2]), pub); err == nil {
+		t.Error("verified log with an entry removed")
+	}
+	if _, err := VerifyLog(strings.NewReader(lines[1]+lines[0]+lines[2]), pub); err == nil {
+		t.Error("verified log with entries reordered")
+	}
+	otherPub, _, _ := sign.Keypair(rand.Reader)
+	if _, err := VerifyLog(strings.NewReader(log.String()), otherPub); err == nil {
+		t.Error("verified log with the wrong key")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestAuditKeyWriteFailure(t *testing.T) {
+	_, priv, _ := sign.Keypair(rand.Reader)
+	a := NewAuditKey(nacl.NewKey(), failWriter{}, priv)
+	nonce := nacl.NewNonce()
+	if box, err := a.Seal(nil, []byte("m"), nonce); err == nil || box != nil {
+		t.Errorf("got %x, %v, want nil and an error", box, err)
+	}
+	if _, err := a.Open(nil, make([]byte, 32), nonce); err == nil || err == errInvalidInput {
+		t.Errorf("got error %v, want write error", err)
+	}
+}
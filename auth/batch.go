@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/kevinburke/nacl"
+)
+
+// VerifyBatchParallel checks each of tags against the message at the same
+// index in messages under key, and returns the results in the same order.
+// The messages are split into contiguous ranges that are verified
+// concurrently by up to workers goroutines; if workers is not positive,
+// GOMAXPROCS goroutines are used. VerifyBatchParallel panics if messages and
+// tags have different lengths.
+func VerifyBatchParallel(messages [][]byte, tags [][Size]byte, key nacl.Key, workers int) []bool {
+	if len(messages) != len(tags) {
+		panic("auth: number of messages and tags differ")
+	}
+	results := make([]bool, len(messages))
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(messages) {
+		workers = len(messages)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		start := i * len(messages) / workers
+		end := (i + 1) * len(messages) / workers
+		go func() {
+			defer wg.Done()
+			for j := start; j < end; j++ {
+				results[j] = Verify(&tags[j], messages[j], key)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func batch(n int, key nacl.Key) ([][]byte, [][Size]byte) {
+	messages := make([][]byte, n)
+	tags := make([][Size]byte, n)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("log line %d", i))
+		tags[i] = *Sum(messages[i], key)
+	}
+	return messages, tags
+}
+
+func TestVerifyBatchParallel(t *testing.T) {
+	key := nacl.NewKey()
+	messages, tags := batch(1000, key)
+	tags[10][0] ^= 0x01
+	messages[999] = []byte("forged")
+	for _, workers := range []int{0, 1, 3, 8, 2000} {
+		results := VerifyBatchParallel(messages, tags, key, workers)
+		if len(results) != len(messages) {
+			t.Fatalf("%d workers: got %d results, want %d", workers, len(results), len(messages))
+		}
+		for i, ok := range results {
+			if want := i != 10 && i != 999; ok != want {
+				t.Errorf("%d workers: message %d: got %t, want %t", workers, i, ok, want)
+			}
+		}
+	}
+	if results := VerifyBatchParallel(nil, nil, key, 4); len(results) != 0 {
+		t.Errorf("got %d results for empty batch", len(results))
+	}
+}
+
+func TestVerifyBatchParallelLengthMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic when lengths differ")
+		}
+	}()
+	key := nacl.NewKey()
+	messages, tags := batch(3, key)
+	VerifyBatchParallel(messages, tags[:2], key, 2)
+}
+
+// Run with -race to check that concurrent batches do not interfere.
+func TestVerifyBatchParallelConcurrent(t *testing.T) {
+	key := nacl.NewKey()
+	messages, tags := batch(200, key)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j, ok := range VerifyBatchParallel(messages, tags, key, 4) {
+				if !ok {
+					t.Errorf("message %d did not verify", j)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkVerifySequential(b *testing.B) {
+	key := nacl.NewKey()
+	messages, tags := batch(1024, key)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range messages {
+			Verify(&tags[j], messages[j], key)
+		}
+	}
+}
+
+func BenchmarkVerifyBatchParallel(b *testing.B) {
+	key := nacl.NewKey()
+	messages, tags := batch(1024, key)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyBatchParallel(messages, tags, key, 0)
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/hmac"
+
+	"github.com/kevinburke/nacl"
+)
+
+// chainLabel separates chained authenticators from authenticators computed
+// with Sum.
+const chainLabel = "nacl auth chain\x00"
+
+// ChainMAC generates an authenticator for entry that also covers prevMAC, the
+// authenticator of the previous entry in a log. For the first entry, prevMAC
+// is all zeros. Because each authenticator depends on every entry before it,
+// removing, reordering or changing an entry invalidates the authenticators of
+// every later entry.
+//
+// A chain cannot show that entries were removed from its end. Store the
+// latest authenticator, or the number of entries, somewhere the attacker
+// cannot modify to detect that.
+func ChainMAC(prevMAC [Size]byte, entry []byte, key nacl.Key) [Size]byte {
+	m := make([]byte, 0, len(chainLabel)+Size+len(entry))
+	m = append(m, chainLabel...)
+	m = append(m, prevMAC[:]...)
+	m = append(m, entry...)
+	return *Sum(m, key)
+}
+
+// VerifyChain checks that macs are the chained authenticators of entries, as
+// produced by calling ChainMAC on each entry in turn, starting from an all
+// zero authenticator. It returns false if any authenticator is incorrect, or
+// if the lengths of entries and macs differ.
+func VerifyChain(entries [][]byte, macs [][Size]byte, key nacl.Key) bool {
+	if len(entries) != len(macs) {
+		return false
+	}
+	var prev [Size]byte
+	for i, entry := range entries {
+		expected := ChainMAC(prev, entry, key)
+		if !hmac.Equal(expected[:], macs[i][:]) {
+			return false
+		}
+		prev = macs[i]
+	}
+	return true
+}
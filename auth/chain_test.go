@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func chain(entries [][]byte, key nacl.Key) [][Size]byte {
+	macs := make([][Size]byte, len(entries))
+	var prev [Size]byte
+	for i, entry := range entries {
+		macs[i] = ChainMAC(prev, entry, key)
+		prev = macs[i]
+	}
+	return macs
+}
+
+func TestVerifyChain(t *testing.T) {
+	key := nacl.NewKey()
+	entries := make([][]byte, 5)
+	for i := range entries {
+		entries[i] = []byte(fmt.Sprintf("entry %d", i))
+	}
+	macs := chain(entries, key)
+	if !VerifyChain(entries, macs, key) {
+		t.Fatal("could not verify chain")
+	}
+	if !VerifyChain(nil, nil, key) {
+		t.Error("could not verify empty chain")
+	}
+	if VerifyChain(entries, macs, nacl.NewKey()) {
+		t.Error("verified chain with wrong key")
+	}
+	if VerifyChain(entries, macs[:4], key) {
+		t.Error("verified chain with missing authenticator")
+	}
+
+	// Remove the middle entry along with its authenticator.
+	removedEntries := append(append([][]byte{}, entries[:2]...), entries[3:]...)
+	removedMACs := append(append([][Size]byte{}, macs[:2]...), macs[3:]...)
+	if VerifyChain(removedEntries, removedMACs, key) {
+		t.Error("verified chain with middle entry removed")
+	}
+
+	swappedEntries := append([][]byte{}, entries...)
+	swappedMACs := append([][Size]byte{}, macs...)
+	swappedEntries[1], swappedEntries[2] = swappedEntries[2], swappedEntries[1]
+	swappedMACs[1], swappedMACs[2] = swappedMACs[2], swappedMACs[1]
+	if VerifyChain(swappedEntries, swappedMACs, key) {
+		t.Error("verified chain with reordered entries")
+	}
+
+	modified := append([][]byte{}, entries...)
+	modified[3] = []byte("entry 3 modified")
+	if VerifyChain(modified, macs, key) {
+		t.Error("verified chain with modified entry")
+	}
+}
+
+func TestChainMACDiffersFromSum(t *testing.T) {
+	key := nacl.NewKey()
+	var prev [Size]byte
+	entry := []byte("entry")
+	mac := ChainMAC(prev, entry, key)
+	if mac == *Sum(append(prev[:], entry...), key) {
+		t.Error("ChainMAC equals Sum of the previous MAC and entry")
+	}
+}
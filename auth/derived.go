@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"github.com/kevinburke/nacl"
+)
+
+// deriveLabel separates derived keys from authenticators computed with Sum
+// over a message that happens to equal a nonce.
+const deriveLabel = "nacl auth derived key"
+
+// deriveKey derives the MAC key for nonce from master.
+func deriveKey(master nacl.Key, nonce nacl.Nonce) nacl.Key {
+	m := make([]byte, 0, len(deriveLabel)+len(nonce))
+	m = append(m, deriveLabel...)
+	m = append(m, nonce[:]...)
+	return Sum(m, master)
+}
+
+// SumDerived generates an authenticator for m using a key derived from master
+// and nonce, and returns the 32-byte digest. Each nonce gives an independent
+// key, so a tag computed under one nonce reveals nothing about tags under any
+// other.
+func SumDerived(m []byte, master nacl.Key, nonce nacl.Nonce) *[Size]byte {
+	return Sum(m, deriveKey(master, nonce))
+}
+
+// VerifyDerived checks that digest is a correct authenticator of m under the
+// key derived from master and nonce. If not, the function returns false.
+func VerifyDerived(digest *[Size]byte, m []byte, master nacl.Key, nonce nacl.Nonce) bool {
+	return Verify(digest, m, deriveKey(master, nonce))
+}
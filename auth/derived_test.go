@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSumDerived(t *testing.T) {
+	master := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	m := []byte("hello world")
+
+	digest := SumDerived(m, master, nonce)
+	if again := SumDerived(m, master, nonce); *again != *digest {
+		t.Error("SumDerived is not deterministic")
+	}
+	if !VerifyDerived(digest, m, master, nonce) {
+		t.Error("could not verify derived digest")
+	}
+	if *digest == *Sum(m, master) {
+		t.Error("derived digest equals digest under master key")
+	}
+	if VerifyDerived(digest, []byte("hello World"), master, nonce) {
+		t.Error("verified modified message")
+	}
+	if VerifyDerived(digest, m, nacl.NewKey(), nonce) {
+		t.Error("verified digest under wrong master key")
+	}
+}
+
+func TestSumDerivedNonces(t *testing.T) {
+	master := nacl.NewKey()
+	m := []byte("hello world")
+	seen := make(map[[Size]byte]bool)
+	for i := 0; i < 100; i++ {
+		nonce := new([24]byte)
+		nonce[23] = byte(i)
+		digest := SumDerived(m, master, nonce)
+		if seen[*digest] {
+			t.Fatalf("nonce %d: repeated digest %x", i, digest)
+		}
+		seen[*digest] = true
+		if i > 0 {
+			nonce[23]--
+			if VerifyDerived(digest, m, master, nonce) {
+				t.Fatalf("nonce %d: digest verified under previous nonce", i)
+			}
+		}
+	}
+}
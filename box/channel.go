@@ -0,0 +1,158 @@
+package box
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/kevinburke/nacl"
+)
+
+// MaxChannelMessageSize is the largest message that can be sent or received on
+// a Channel.
+const MaxChannelMessageSize = 16 << 20
+
+var (
+	errChannelMessageTooLong = errors.New("box: channel message too long")
+	errNoncesExhausted       = errors.New("box: channel nonces exhausted")
+)
+
+// A Channel sends and receives boxes over a long-lived connection, managing
+// nonces automatically. Each message is written as a 4 byte big-endian length,
+// followed by the box.
+//
+// Nonces are 64-bit counters, stored big-endian in the last 8 bytes of the
+// nonce. Following the convention described in the nacl.Nonce documentation,
+// the peer with the lexicographically smaller public key sends messages with
+// nonces 1, 3, 5, etc., and the peer with the larger public key sends messages
+// with nonces 2, 4, 6, etc. Because the receive counter advances with every
+// message, a replayed, reordered or dropped message fails to open.
+//
+// Send and Recv may be called concurrently with each other, but not with
+// themselves.
+type Channel struct {
+	conn      io.ReadWriter
+	sharedKey nacl.Key
+
+	mu          sync.Mutex
+	sendCounter uint64
+	recvCounter uint64
+}
+
+// NewStatefulChannel returns a Channel that sends and receives messages on
+// conn, encrypted between the key pair (ourPublicKey, ourPrivateKey) and
+// theirPublicKey.
+func NewStatefulChannel(conn io.ReadWriter, ourPublicKey, ourPrivateKey, theirPublicKey nacl.Key) *Channel {
+	c := &Channel{
+		conn:      conn,
+		sharedKey: Precompute(theirPublicKey, ourPrivateKey),
+	}
+	if bytes.Compare(ourPublicKey[:], theirPublicKey[:]) < 0 {
+		c.sendCounter, c.recvCounter = 1, 2
+	} else {
+		c.sendCounter, c.recvCounter = 2, 1
+	}
+	return c
+}
+
+func counterNonce(counter uint64) nacl.Nonce {
+	nonce := new([24]byte)
+	binary.BigEndian.PutUint64(nonce[16:], counter)
+	return nonce
+}
+
+// next returns the nonce for the current value of *counter and advances it.
+func (c *Channel) next(counter *uint64) (nacl.Nonce, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if *counter > ^uint64(0)-2 {
+		return nil, errNoncesExhausted
+	}
+	nonce := counterNonce(*counter)
+	*counter += 2
+	return nonce, nil
+}
+
+// Send encrypts message with the next send nonce and writes it to the
+// connection.
+func (c *Channel) Send(message []byte) error {
+	if len(message) > MaxChannelMessageSize {
+		return errChannelMessageTooLong
+	}
+	nonce, err := c.next(&c.sendCounter)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 4, 4+len(message)+Overhead)
+	binary.BigEndian.PutUint32(frame, uint32(len(message)+Overhead))
+	frame = SealAfterPrecomputation(frame, message, nonce, c.sharedKey)
+	_, err = c.conn.Write(frame)
+	return err
+}
+
+// Recv reads the next message from the connection and decrypts it with the
+// next receive nonce. If the message cannot be decrypted, the receive nonce
+// is not advanced.
+func (c *Channel) Recv() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxChannelMessageSize+Overhead {
+		return nil, errChannelMessageTooLong
+	}
+	box := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, box); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	nonce := counterNonce(c.recvCounter)
+	c.mu.Unlock()
+	message, ok := OpenAfterPrecomputation(nil, box, nonce, c.sharedKey)
+	if !ok {
+		return nil, errInvalidInput
+	}
+	if _, err := c.next(&c.recvCounter); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// SaveState writes the channel's send and receive counters to w, so the
+// channel can be resumed with LoadState after reconnecting. The state must be
+// saved after the last message was sent or received; resuming from an older
+// state would reuse nonces.
+func (c *Channel) SaveState(w io.Writer) error {
+	var state [16]byte
+	c.mu.Lock()
+	binary.BigEndian.PutUint64(state[:8], c.sendCounter)
+	binary.BigEndian.PutUint64(state[8:], c.recvCounter)
+	c.mu.Unlock()
+	_, err := w.Write(state[:])
+	return err
+}
+
+// LoadState reads counters written by SaveState from r, and uses them for
+// subsequent calls to Send and Recv.
+func (c *Channel) LoadState(r io.Reader) error {
+	var state [16]byte
+	if _, err := io.ReadFull(r, state[:]); err != nil {
+		return err
+	}
+	send := binary.BigEndian.Uint64(state[:8])
+	recv := binary.BigEndian.Uint64(state[8:])
+	if send%2 == recv%2 {
+		return errors.New("box: invalid channel state")
+	}
+	c.mu.Lock()
+	c.sendCounter, c.recvCounter = send, recv
+	c.mu.Unlock()
+	return nil
+}
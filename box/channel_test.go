@@ -0,0 +1,156 @@
+package box
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+type peer struct {
+	pub, priv nacl.Key
+}
+
+func newPeers(t *testing.T) (a, b peer) {
+	t.Helper()
+	var err error
+	a.pub, a.priv, err = GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.pub, b.priv, err = GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func newChannels(a, b peer) (*Channel, *Channel, func()) {
+	c1, c2 := net.Pipe()
+	ca := NewStatefulChannel(c1, a.pub, a.priv, b.pub)
+	cb := NewStatefulChannel(c2, b.pub, b.priv, a.pub)
+	return ca, cb, func() {
+		c1.Close()
+		c2.Close()
+	}
+}
+
+func exchange(t *testing.T, from, to *Channel, message []byte) {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- from.Send(message)
+	}()
+	got, err := to.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, message) {
+		t.Fatalf("got %q, want %q", got, message)
+	}
+}
+
+func TestChannel(t *testing.T) {
+	a, b := newPeers(t)
+	ca, cb, closer := newChannels(a, b)
+	defer closer()
+	for i := 0; i < 5; i++ {
+		exchange(t, ca, cb, []byte(fmt.Sprintf("ping %d", i)))
+		exchange(t, cb, ca, []byte(fmt.Sprintf("pong %d", i)))
+	}
+	exchange(t, ca, cb, []byte("one"))
+	exchange(t, ca, cb, []byte("two"))
+	exchange(t, ca, cb, []byte{})
+}
+
+func TestChannelNonces(t *testing.T) {
+	a, b := newPeers(t)
+	ca, cb, closer := newChannels(a, b)
+	defer closer()
+	if ca.sendCounter == cb.sendCounter {
+		t.Fatalf("both peers send with nonce %d", ca.sendCounter)
+	}
+	if ca.sendCounter != cb.recvCounter || cb.sendCounter != ca.recvCounter {
+		t.Fatalf("send and receive counters do not match")
+	}
+	if ca.sendCounter+cb.sendCounter != 3 {
+		t.Fatalf("expected counters 1 and 2, got %d and %d", ca.sendCounter, cb.sendCounter)
+	}
+}
+
+func TestChannelReplay(t *testing.T) {
+	a, b := newPeers(t)
+	var buf bytes.Buffer
+	ca := NewStatefulChannel(&buf, a.pub, a.priv, b.pub)
+	if err := ca.Send([]byte("transfer $100")); err != nil {
+		t.Fatal(err)
+	}
+	frame := append([]byte{}, buf.Bytes()...)
+	buf.Write(frame)
+
+	cb := NewStatefulChannel(&buf, b.pub, b.priv, a.pub)
+	if _, err := cb.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Recv(); err != errInvalidInput {
+		t.Errorf("expected replayed message to fail, got %v", err)
+	}
+}
+
+func TestChannelState(t *testing.T) {
+	a, b := newPeers(t)
+	ca, cb, closer := newChannels(a, b)
+	exchange(t, ca, cb, []byte("hello"))
+	exchange(t, cb, ca, []byte("hi"))
+	exchange(t, ca, cb, []byte("bye"))
+	var stateA, stateB bytes.Buffer
+	if err := ca.SaveState(&stateA); err != nil {
+		t.Fatal(err)
+	}
+	if err := cb.SaveState(&stateB); err != nil {
+		t.Fatal(err)
+	}
+	closer()
+
+	// Reconnect.
+	ca2, cb2, closer := newChannels(a, b)
+	defer closer()
+	if err := ca2.LoadState(&stateA); err != nil {
+		t.Fatal(err)
+	}
+	if err := cb2.LoadState(&stateB); err != nil {
+		t.Fatal(err)
+	}
+	if ca2.sendCounter != 5 && ca2.sendCounter != 6 {
+		t.Errorf("got send counter %d after resuming, want 5 or 6", ca2.sendCounter)
+	}
+	exchange(t, ca2, cb2, []byte("back again"))
+	exchange(t, cb2, ca2, []byte("welcome back"))
+
+	if err := ca2.LoadState(bytes.NewReader(make([]byte, 16))); err == nil {
+		t.Errorf("expected error loading invalid state")
+	}
+	if err := ca2.LoadState(bytes.NewReader(make([]byte, 8))); err == nil {
+		t.Errorf("expected error loading short state")
+	}
+}
+
+func TestChannelTooLong(t *testing.T) {
+	a, b := newPeers(t)
+	var buf bytes.Buffer
+	ca := NewStatefulChannel(&buf, a.pub, a.priv, b.pub)
+	if err := ca.Send(make([]byte, MaxChannelMessageSize+1)); err != errChannelMessageTooLong {
+		t.Errorf("expected message too long error, got %v", err)
+	}
+	buf.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	cb := NewStatefulChannel(&buf, b.pub, b.priv, a.pub)
+	if _, err := cb.Recv(); err != errChannelMessageTooLong {
+		t.Errorf("expected message too long error, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package deniable encrypts and authenticates messages between two parties
+// so that either of them could have written any message, in the style of
+// Off-the-Record messaging.
+//
+// The parties' Curve25519 keys give them a shared secret, from which an
+// encryption key and a MAC key are derived with HKDF-SHA-512. Messages are
+// encrypted with XSalsa20 under the encryption key, and authenticated with
+// HMAC-SHA-512-256 (see package auth) under the MAC key. A box is the 32 byte
+// tag followed by the ciphertext.
+//
+// The recipient of a box knows it came from the sender, since only the two of
+// them hold the MAC key. But the recipient cannot convince anyone else of
+// that, since they could have computed the same tag themselves: a box sealed
+// by the sender is identical to one the recipient seals with the same nonce.
+// Compare box.SealSigned, whose signature anyone can verify, so the sender
+// cannot later deny having written the message.
+//
+// Once a conversation is over, the parties can go further and publish the
+// MAC key, returned by MACKey. After that, anyone can modify a ciphertext and
+// compute a valid tag for it, so a transcript proves nothing about who wrote
+// it. The encryption key stays secret, so publishing the MAC key does not
+// reveal the messages.
+package deniable
+
+import (
+	"crypto/sha512"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/box"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/salsa20"
+)
+
+// Overhead is the number of bytes of overhead when sealing a message.
+const Overhead = auth.Size
+
+// keys derives the encryption and MAC keys shared by the holders of
+// privateKey and the private key for peersPublicKey.
+func keys(peersPublicKey, privateKey nacl.Key) (encKey, macKey nacl.Key) {
+	shared := box.Precompute(peersPublicKey, privateKey)
+	var out [64]byte
+	r := hkdf.New(sha512.New, shared[:], nil, []byte("nacl deniable"))
+	if _, err := io.ReadFull(r, out[:]); err != nil {
+		panic(err)
+	}
+	encKey, macKey = new([32]byte), new([32]byte)
+	copy(encKey[:], out[:32])
+	copy(macKey[:], out[32:])
+	return encKey, macKey
+}
+
+// MACKey returns the MAC key shared by the holders of privateKey and the
+// private key for peersPublicKey. Both parties compute the same key. It can
+// be published after a conversation to make its transcript forgeable.
+func MACKey(peersPublicKey, privateKey nacl.Key) nacl.Key {
+	_, macKey := keys(peersPublicKey, privateKey)
+	return macKey
+}
+
+// macInput returns the data covered by the tag: the nonce followed by the
+// ciphertext.
+func macInput(ciphertext []byte, nonce nacl.Nonce) []byte {
+	m := make([]byte, 0, len(nonce)+len(ciphertext))
+	m = append(m, nonce[:]...)
+	return append(m, ciphertext...)
+}
+
+// Seal encrypts and authenticates message for the holder of the private key
+// for peersPublicKey, and appends the result to out, which must not overlap
+// message. The output is Overhead bytes longer than message. The nonce must be
+// unique for each distinct message for a given pair of keys; the same nonce
+// must not be used by both parties.
+func Seal(out, message []byte, nonce nacl.Nonce, peersPublicKey, privateKey nacl.Key) []byte {
+	encKey, macKey := keys(peersPublicKey, privateKey)
+	ciphertext := make([]byte, len(message))
+	salsa20.XORKeyStream(ciphertext, message, nonce[:], encKey)
+	tag := auth.Sum(macInput(ciphertext, nonce), macKey)
+	out = append(out, tag[:]...)
+	return append(out, ciphertext...)
+}
+
+// Open authenticates and decrypts a box produced by Seal by the holder of the
+// private key for peersPublicKey, and appends the message to out. It returns
+// false if the box is invalid.
+func Open(out, sealed []byte, nonce nacl.Nonce, peersPublicKey, privateKey nacl.Key) ([]byte, bool) {
+	if len(sealed) < Overhead {
+		return nil, false
+	}
+	encKey, macKey := keys(peersPublicKey, privateKey)
+	var tag [auth.Size]byte
+	copy(tag[:], sealed)
+	ciphertext := sealed[Overhead:]
+	if !auth.Verify(&tag, macInput(ciphertext, nonce), macKey) {
+		return nil, false
+	}
+	message := make([]byte, len(ciphertext))
+	salsa20.XORKeyStream(message, ciphertext, nonce[:], encKey)
+	return append(out, message...), true
+}
@@ -0,0 +1,103 @@
+package deniable
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/box"
+)
+
+func TestSealOpen(t *testing.T) {
+	alicePub, alicePriv, _ := box.GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := box.GenerateKey(rand.Reader)
+	message := []byte("meet me at midnight")
+	nonce := nacl.NewNonce()
+
+	sealed := Seal(nil, message, nonce, bobPub, alicePriv)
+	if len(sealed) != len(message)+Overhead {
+		t.Errorf("got %d byte box, want %d", len(sealed), len(message)+Overhead)
+	}
+	opened, ok := Open(nil, sealed, nonce, alicePub, bobPriv)
+	if !ok {
+		t.Fatal("Bob could not open Alice's box")
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	// Bob can send to Alice as well.
+	replyNonce := nacl.NewNonce()
+	reply := Seal(nil, []byte("ok"), replyNonce, alicePub, bobPriv)
+	if opened, ok := Open(nil, reply, replyNonce, bobPub, alicePriv); !ok || string(opened) != "ok" {
+		t.Errorf("Alice could not open Bob's reply: got %q, %t", opened, ok)
+	}
+	if _, ok := Open(nil, reply, nonce, bobPub, alicePriv); ok {
+		t.Error("opened box with the wrong nonce")
+	}
+
+	evePub, evePriv, _ := box.GenerateKey(rand.Reader)
+	if _, ok := Open(nil, sealed, nonce, evePub, bobPriv); ok {
+		t.Error("opened box claiming to be from another sender")
+	}
+	if _, ok := Open(nil, sealed, nonce, alicePub, evePriv); ok {
+		t.Error("third party opened box")
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, ok := Open(nil, sealed, nonce, alicePub, bobPriv); ok {
+		t.Error("opened modified box")
+	}
+	if _, ok := Open(nil, sealed[:Overhead-1], nonce, alicePub, bobPriv); ok {
+		t.Error("opened short box")
+	}
+}
+
+func TestSymmetric(t *testing.T) {
+	alicePub, alicePriv, _ := box.GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := box.GenerateKey(rand.Reader)
+	if *MACKey(bobPub, alicePriv) != *MACKey(alicePub, bobPriv) {
+		t.Fatal("parties derived different MAC keys")
+	}
+	// A box "from Alice" that Bob made himself is identical to one Alice
+	// made, so it proves nothing to a third party.
+	message := []byte("I owe Bob $100")
+	nonce := nacl.NewNonce()
+	fromAlice := Seal(nil, message, nonce, bobPub, alicePriv)
+	forgedByBob := Seal(nil, message, nonce, alicePub, bobPriv)
+	if !bytes.Equal(fromAlice, forgedByBob) {
+		t.Error("box forged by the recipient differs from the sender's")
+	}
+}
+
+func TestPublishedMACKey(t *testing.T) {
+	alicePub, alicePriv, _ := box.GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := box.GenerateKey(rand.Reader)
+	nonce := nacl.NewNonce()
+	sealed := Seal(nil, []byte("attack at dawn"), nonce, bobPub, alicePriv)
+
+	// With the published MAC key, anyone can modify a ciphertext and produce
+	// a valid tag for it, without knowing the encryption key.
+	macKey := MACKey(bobPub, alicePriv)
+	forged := append([]byte{}, sealed...)
+	ciphertext := forged[Overhead:]
+	for i, c := range []byte("attack at dusk") {
+		ciphertext[i] ^= c ^ "attack at dawn"[i]
+	}
+	tag := auth.Sum(macInput(ciphertext, nonce), macKey)
+	copy(forged, tag[:])
+	opened, ok := Open(nil, forged, nonce, alicePub, bobPriv)
+	if !ok {
+		t.Fatal("could not open box forged with the published MAC key")
+	}
+	if string(opened) != "attack at dusk" {
+		t.Errorf("got %q, want %q", opened, "attack at dusk")
+	}
+
+	// The MAC key does not decrypt messages.
+	encKey, _ := keys(bobPub, alicePriv)
+	if *encKey == *macKey {
+		t.Error("encryption key equals MAC key")
+	}
+}
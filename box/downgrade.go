@@ -0,0 +1,23 @@
+package box
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// Downgrade authenticates box and returns it along with the shared key for
+// peerPublic and myPrivate, as computed by Precompute. A box is a secretbox
+// sealed with the shared key, so the returned ciphertext is box itself, and
+// can be opened with secretbox.Open, the same nonce and the shared key. ok is
+// false if box cannot be opened.
+//
+// The shared key opens every box between the two key pairs, in either
+// direction, and can be used to forge boxes from either party. It must be
+// protected as carefully as the private keys.
+func Downgrade(box []byte, nonce nacl.Nonce, peerPublic, myPrivate nacl.Key) (secretboxCiphertext []byte, sharedKey nacl.Key, ok bool) {
+	sharedKey = Precompute(peerPublic, myPrivate)
+	if _, ok := secretbox.Open(nil, box, nonce, sharedKey); !ok {
+		return nil, nil, false
+	}
+	return box, sharedKey, true
+}
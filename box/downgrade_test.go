@@ -0,0 +1,35 @@
+package box
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+func TestDowngrade(t *testing.T) {
+	alicePub, alicePriv, _ := GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := GenerateKey(rand.Reader)
+	nonce := nacl.NewNonce()
+	message := []byte("archived record")
+	sealed := Seal(nil, message, nonce, bobPub, alicePriv)
+
+	ciphertext, sharedKey, ok := Downgrade(sealed, nonce, alicePub, bobPriv)
+	if !ok {
+		t.Fatal("could not downgrade box")
+	}
+	opened, ok := secretbox.Open(nil, ciphertext, nonce, sharedKey)
+	if !ok {
+		t.Fatal("could not open downgraded box with secretbox")
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	sealed[len(sealed)-1] ^= 0x01
+	if _, _, ok := Downgrade(sealed, nonce, alicePub, bobPriv); ok {
+		t.Error("downgraded modified box")
+	}
+}
@@ -0,0 +1,186 @@
+// Package hpke implements single-shot Hybrid Public Key Encryption, as in
+// RFC 9180, in base mode, with XSalsa20-Poly1305 as the AEAD.
+//
+// The KEM is DHKEM(X25519, HKDF-SHA256) and the KDF is HKDF-SHA256, exactly
+// as specified in RFC 9180, so the KEM output and key schedule can be checked
+// against the RFC's test vectors. The AEAD is not one of the RFC's: it is
+// secretbox with associated data, as produced by secretbox.SealWithADReader,
+// using Nk = 32, Nn = 24 and Nt = 16, and the AEAD identifier AEADID, which
+// is not registered with IANA. Other HPKE implementations interoperate only
+// if they implement the same AEAD.
+//
+// Seal and Open each use a context for a single message, with sequence
+// number 0, so the nonce is the base nonce from the key schedule.
+package hpke
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/scalarmult"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+const (
+	// KEMID is the RFC 9180 identifier of DHKEM(X25519, HKDF-SHA256).
+	KEMID = 0x0020
+	// KDFID is the RFC 9180 identifier of HKDF-SHA256.
+	KDFID = 0x0001
+	// AEADID identifies XSalsa20-Poly1305 in the suite ID. It is not
+	// registered with IANA.
+	AEADID = 0xfffe
+
+	// EncapSize is the size, in bytes, of an encapsulated key.
+	EncapSize = 32
+
+	nk = 32 // key size
+	nn = 24 // nonce size
+	nh = sha256.Size
+)
+
+var (
+	errInvalidInput = errors.New("hpke: Could not decrypt invalid input")
+	errLowOrderKey  = errors.New("hpke: low order public key")
+)
+
+var (
+	kemSuiteID  = []byte{'K', 'E', 'M', KEMID >> 8, KEMID & 0xff}
+	hpkeSuiteID = []byte{'H', 'P', 'K', 'E', KEMID >> 8, KEMID & 0xff, KDFID >> 8, KDFID & 0xff, AEADID >> 8, AEADID & 0xff}
+)
+
+// labeledExtract is LabeledExtract from RFC 9180, Section 4.
+func labeledExtract(suiteID, salt []byte, label string, ikm []byte) []byte {
+	if salt == nil {
+		salt = make([]byte, nh)
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte("HPKE-v1"))
+	mac.Write(suiteID)
+	mac.Write([]byte(label))
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+// labeledExpand is LabeledExpand from RFC 9180, Section 4. length must be
+// at most 255*nh.
+func labeledExpand(suiteID, prk []byte, label string, info []byte, length int) []byte {
+	labeledInfo := []byte{byte(length >> 8), byte(length)}
+	labeledInfo = append(labeledInfo, "HPKE-v1"...)
+	labeledInfo = append(labeledInfo, suiteID...)
+	labeledInfo = append(labeledInfo, label...)
+	labeledInfo = append(labeledInfo, info...)
+
+	// HKDF-Expand, RFC 5869, Section 2.3.
+	mac := hmac.New(sha256.New, prk)
+	var out, t []byte
+	for counter := byte(1); len(out) < length; counter++ {
+		mac.Reset()
+		mac.Write(t)
+		mac.Write(labeledInfo)
+		mac.Write([]byte{counter})
+		t = mac.Sum(nil)
+		out = append(out, t...)
+	}
+	return out[:length]
+}
+
+// DeriveKeyPair deterministically derives a key pair from ikm, which should
+// have at least 32 bytes of entropy, as in RFC 9180, Section 7.1.3.
+func DeriveKeyPair(ikm []byte) (publicKey, privateKey nacl.Key) {
+	dkpPRK := labeledExtract(kemSuiteID, nil, "dkp_prk", ikm)
+	privateKey = new([32]byte)
+	copy(privateKey[:], labeledExpand(kemSuiteID, dkpPRK, "sk", nil, 32))
+	return scalarmult.Base(privateKey), privateKey
+}
+
+// sharedSecret is DH followed by ExtractAndExpand, RFC 9180, Section 4.1.
+func sharedSecret(privateKey, peersPublicKey nacl.Key, enc, recipientPub []byte) ([]byte, error) {
+	dh := scalarmult.Mult(privateKey, peersPublicKey)
+	var acc byte
+	for _, b := range dh {
+		acc |= b
+	}
+	if acc == 0 {
+		return nil, errLowOrderKey
+	}
+	kemContext := append(append([]byte{}, enc...), recipientPub...)
+	eaePRK := labeledExtract(kemSuiteID, nil, "eae_prk", dh[:])
+	return labeledExpand(kemSuiteID, eaePRK, "shared_secret", kemContext, nh), nil
+}
+
+// keySchedule is KeySchedule from RFC 9180, Section 5.1, in base mode, for
+// the suite with the given ID and AEAD key and nonce sizes.
+func keySchedule(suiteID, sharedSecret, info []byte, keySize, nonceSize int) (key, baseNonce, exporterSecret []byte) {
+	pskIDHash := labeledExtract(suiteID, nil, "psk_id_hash", nil)
+	infoHash := labeledExtract(suiteID, nil, "info_hash", info)
+	context := []byte{0x00} // mode_base
+	context = append(context, pskIDHash...)
+	context = append(context, infoHash...)
+
+	secret := labeledExtract(suiteID, sharedSecret, "secret", nil)
+	key = labeledExpand(suiteID, secret, "key", context, keySize)
+	baseNonce = labeledExpand(suiteID, secret, "base_nonce", context, nonceSize)
+	exporterSecret = labeledExpand(suiteID, secret, "exp", context, nh)
+	return key, baseNonce, exporterSecret
+}
+
+func aeadKeys(sharedSecret, info []byte) (nacl.Key, nacl.Nonce) {
+	k, n, _ := keySchedule(hpkeSuiteID, sharedSecret, info, nk, nn)
+	key, nonce := new([32]byte), new([24]byte)
+	copy(key[:], k)
+	copy(nonce[:], n)
+	return key, nonce
+}
+
+// Seal encrypts message to recipientPub, and authenticates it and aad, using
+// a fresh ephemeral key pair. It returns the encapsulated key, which the
+// recipient needs along with the ciphertext, and the ciphertext, which is
+// secretbox.Overhead bytes longer than message. info binds the message to
+// an application context; the recipient must pass the same info to Open.
+func Seal(message, info, aad []byte, recipientPub nacl.Key) (encap []byte, ciphertext []byte, err error) {
+	var ikm [32]byte
+	if _, err := randombytes.Read(ikm[:]); err != nil {
+		return nil, nil, err
+	}
+	ephemeralPub, ephemeralPriv := DeriveKeyPair(ikm[:])
+	return seal(message, info, aad, recipientPub, ephemeralPub, ephemeralPriv)
+}
+
+func seal(message, info, aad []byte, recipientPub, ephemeralPub, ephemeralPriv nacl.Key) (encap []byte, ciphertext []byte, err error) {
+	encap = ephemeralPub[:]
+	shared, err := sharedSecret(ephemeralPriv, recipientPub, encap, recipientPub[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, nonce := aeadKeys(shared, info)
+	ciphertext, err = secretbox.SealWithADReader(message, bytes.NewReader(aad), nonce, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encap, ciphertext, nil
+}
+
+// Open decrypts a ciphertext produced by Seal, given the encapsulated key,
+// and checks that it was sealed with the same info and aad.
+func Open(encap, ciphertext, info, aad []byte, recipientPriv nacl.Key) ([]byte, error) {
+	if len(encap) != EncapSize {
+		return nil, errInvalidInput
+	}
+	ephemeralPub := new([32]byte)
+	copy(ephemeralPub[:], encap)
+	recipientPub := scalarmult.Base(recipientPriv)
+	shared, err := sharedSecret(recipientPriv, ephemeralPub, encap, recipientPub[:])
+	if err != nil {
+		return nil, errInvalidInput
+	}
+	key, nonce := aeadKeys(shared, info)
+	message, err := secretbox.OpenWithADReader(ciphertext, bytes.NewReader(aad), nonce, key)
+	if err != nil {
+		return nil, errInvalidInput
+	}
+	return message, nil
+}
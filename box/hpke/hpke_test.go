@@ -0,0 +1,173 @@
+package hpke
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/kevinburke/nacl/box"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestRFC9180Vectors checks the KEM and key schedule against RFC 9180,
+// Appendix A.1.1: DHKEM(X25519, HKDF-SHA256), HKDF-SHA256, AES-128-GCM, in
+// base mode. Only the AEAD differs from this package, so the same code, with
+// the RFC's suite ID and AES-128-GCM's key and nonce sizes, must produce the
+// RFC's key, base nonce and exporter secret.
+func TestRFC9180Vectors(t *testing.T) {
+	info := mustDecodeHex(t, "4f6465206f6e2061204772656369616e2055726e")
+	ikmE := mustDecodeHex(t, "7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234")
+	ikmR := mustDecodeHex(t, "6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037")
+
+	pubE, privE := DeriveKeyPair(ikmE)
+	pubR, privR := DeriveKeyPair(ikmR)
+	for _, tt := range []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"skEm", privE[:], "52c4a758a802cd8b936eceea314432798d5baf2d7e9235dc084ab1b9cfa2f736"},
+		{"pkEm", pubE[:], "37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431"},
+		{"skRm", privR[:], "4612c550263fc8ad58375df3f557aac531d26850903e55a9f23f21d8534e8ac8"},
+		{"pkRm", pubR[:], "3948cfe0ad1ddb695d780e59077195da6c56506b027329794ab02bca80815c4d"},
+	} {
+		if hex.EncodeToString(tt.got) != tt.want {
+			t.Errorf("%s: got %x, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	shared, err := sharedSecret(privE, pubR, pubE[:], pubR[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc"; hex.EncodeToString(shared) != want {
+		t.Errorf("shared_secret: got %x, want %s", shared, want)
+	}
+	// The recipient computes the same shared secret.
+	recipientShared, err := sharedSecret(privR, pubE, pubE[:], pubR[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recipientShared, shared) {
+		t.Error("recipient computed a different shared secret")
+	}
+
+	aesSuiteID := []byte{'H', 'P', 'K', 'E', 0x00, 0x20, 0x00, 0x01, 0x00, 0x01}
+	key, baseNonce, exporterSecret := keySchedule(aesSuiteID, shared, info, 16, 12)
+	for _, tt := range []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"key", key, "4531685d41d65f03dc48f6b8302c05b0"},
+		{"base_nonce", baseNonce, "56d890e5accaaf011cff4b7d"},
+		{"exporter_secret", exporterSecret, "45ff1c2e220db587171952c0592d5f5ebe103f1561a2614e38f2ffd47e99e3f8"},
+	} {
+		if hex.EncodeToString(tt.got) != tt.want {
+			t.Errorf("%s: got %x, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestKnownAnswer pins the output of this package's suite for the RFC 9180
+// keys, so that the encoding does not change by accident.
+func TestKnownAnswer(t *testing.T) {
+	info := mustDecodeHex(t, "4f6465206f6e2061204772656369616e2055726e")
+	pubE, privE := DeriveKeyPair(mustDecodeHex(t, "7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234"))
+	pubR, privR := DeriveKeyPair(mustDecodeHex(t, "6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037"))
+	message := []byte("Beauty is truth, truth beauty")
+	aad := []byte("Count-0")
+
+	encap, ciphertext, err := seal(message, info, aad, pubR, pubE, privE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encap, pubE[:]) {
+		t.Errorf("got encap %x, want %x", encap, pubE[:])
+	}
+	if want := "683915a6baac99db5b672a469ecca96a204ed32c7b37a7a1b26e4df0f0092953186c13cf90a068109999a255ed"; hex.EncodeToString(ciphertext) != want {
+		t.Errorf("got ciphertext %x, want %s", ciphertext, want)
+	}
+	opened, err := Open(encap, ciphertext, info, aad, privR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("hello world")
+	info := []byte("example app v1")
+	aad := []byte("header")
+	encap, ciphertext, err := Seal(message, info, aad, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encap) != EncapSize {
+		t.Errorf("got encap length %d, want %d", len(encap), EncapSize)
+	}
+	opened, err := Open(encap, ciphertext, info, aad, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	encap2, _, err := Seal(message, info, aad, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(encap, encap2) {
+		t.Error("two seals used the same ephemeral key")
+	}
+
+	modified := append([]byte{}, ciphertext...)
+	modified[0] ^= 1
+	modifiedEncap := append([]byte{}, encap...)
+	modifiedEncap[0] ^= 1
+	tests := []struct {
+		name                         string
+		encap, ciphertext, info, aad []byte
+		priv                         []byte
+	}{
+		{"wrong key", encap, ciphertext, info, aad, otherPriv[:]},
+		{"wrong info", encap, ciphertext, []byte("other app"), aad, priv[:]},
+		{"wrong aad", encap, ciphertext, info, []byte("other header"), priv[:]},
+		{"modified ciphertext", encap, modified, info, aad, priv[:]},
+		{"modified encap", modifiedEncap, ciphertext, info, aad, priv[:]},
+		{"short encap", encap[:EncapSize-1], ciphertext, info, aad, priv[:]},
+		{"zero encap", make([]byte, EncapSize), ciphertext, info, aad, priv[:]},
+		{"short ciphertext", encap, ciphertext[:10], info, aad, priv[:]},
+	}
+	for _, tt := range tests {
+		key := new([32]byte)
+		copy(key[:], tt.priv)
+		if _, err := Open(tt.encap, tt.ciphertext, tt.info, tt.aad, key); err != errInvalidInput {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, errInvalidInput)
+		}
+	}
+
+	if _, _, err := Seal(message, info, aad, new([32]byte)); err != errLowOrderKey {
+		t.Errorf("zero public key: got error %v, want %v", err, errLowOrderKey)
+	}
+}
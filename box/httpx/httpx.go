@@ -0,0 +1,221 @@
+// Package httpx encrypts the bodies of HTTP requests and responses with box,
+// so they are protected end to end even when the transport is not.
+//
+// Each body is sealed with secretbox under a random nonce and a key derived
+// from the box shared key between client and server. Requests and responses
+// use different derived keys, so a request cannot be reflected back to the
+// client as a response, and each response is bound to the nonce of the
+// request it answers, so an old response cannot be replayed for a new
+// request. The nonce and the sender's public key are sent, hex encoded, in
+// the X-NaCl-Nonce and X-NaCl-Sender headers. Only bodies are protected: the
+// method, URL, status code and other headers are sent in the clear and are
+// not authenticated.
+package httpx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/box"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+const (
+	nonceHeader  = "X-NaCl-Nonce"
+	senderHeader = "X-NaCl-Sender"
+)
+
+// Labels for the keys that seal each direction of the exchange.
+const (
+	requestLabel  = "nacl httpx request"
+	responseLabel = "nacl httpx response"
+)
+
+var (
+	errInvalidInput = errors.New("httpx: Could not decrypt invalid input")
+	errWrongSender  = errors.New("httpx: response was not sent by the server")
+)
+
+func decodeHeader(h http.Header, name string, out []byte) error {
+	b, err := hex.DecodeString(h.Get(name))
+	if err != nil || len(b) != len(out) {
+		return fmt.Errorf("httpx: missing or invalid %s header", name)
+	}
+	copy(out, b)
+	return nil
+}
+
+// directionKey derives the key for one direction of the exchange from the
+// box shared key between peersPublicKey and privateKey.
+func directionKey(label string, peersPublicKey, privateKey nacl.Key) nacl.Key {
+	return auth.Sum([]byte(label), box.Precompute(peersPublicKey, privateKey))
+}
+
+// openBody reads the nonce and sender headers from h and opens body with the
+// label key shared by the sender and privateKey, checking that it was sealed
+// with associated data ad. It returns the plaintext, the nonce and the
+// sender's public key.
+func openBody(h http.Header, body []byte, label string, ad []byte, privateKey nacl.Key) ([]byte, nacl.Nonce, nacl.Key, error) {
+	nonce, sender := new([24]byte), new([32]byte)
+	if err := decodeHeader(h, nonceHeader, nonce[:]); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := decodeHeader(h, senderHeader, sender[:]); err != nil {
+		return nil, nil, nil, err
+	}
+	key := directionKey(label, sender, privateKey)
+	plain, err := secretbox.OpenWithADReader(body, bytes.NewReader(ad), nonce, key)
+	if err != nil {
+		return nil, nil, nil, errInvalidInput
+	}
+	return plain, nonce, sender, nil
+}
+
+// sealBody seals body for peersPublicKey with the label key and associated
+// data ad under a random nonce, and sets the nonce and sender headers in h.
+// It returns the sealed body and the nonce.
+func sealBody(h http.Header, body []byte, label string, ad []byte, peersPublicKey, publicKey, privateKey nacl.Key) ([]byte, nacl.Nonce) {
+	nonce := nacl.NewNonce()
+	h.Set(nonceHeader, hex.EncodeToString(nonce[:]))
+	h.Set(senderHeader, hex.EncodeToString(publicKey[:]))
+	key := directionKey(label, peersPublicKey, privateKey)
+	// Reading the associated data from a bytes.Reader cannot fail.
+	sealed, _ := secretbox.SealWithADReader(body, bytes.NewReader(ad), nonce, key)
+	return sealed, nonce
+}
+
+type roundTripper struct {
+	base       http.RoundTripper
+	serverPub  nacl.Key
+	clientPub  nacl.Key
+	clientPriv nacl.Key
+}
+
+// NewEncryptedHTTPClient returns an http.Client that seals request bodies
+// for serverPub with clientPriv, and opens response bodies, which must be
+// sealed by serverPub. A response that is not, or that cannot be opened, is
+// returned as an error from the client's methods. Requests without a body
+// are sent with a sealed empty body.
+//
+// Servers can use NewHandler to handle the requests.
+func NewEncryptedHTTPClient(serverPub, clientPub, clientPriv nacl.Key) *http.Client {
+	return &http.Client{
+		Transport: &roundTripper{
+			base:       http.DefaultTransport,
+			serverPub:  serverPub,
+			clientPub:  clientPub,
+			clientPriv: clientPriv,
+		},
+	}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// RoundTrippers must not modify the request, so send a copy.
+	r2 := new(http.Request)
+	*r2 = *req
+	r2.Header = make(http.Header, len(req.Header)+2)
+	for k, v := range req.Header {
+		r2.Header[k] = append([]string(nil), v...)
+	}
+	sealed, reqNonce := sealBody(r2.Header, body, requestLabel, nil, rt.serverPub, rt.clientPub, rt.clientPriv)
+	r2.Body = ioutil.NopCloser(bytes.NewReader(sealed))
+	r2.ContentLength = int64(len(sealed))
+	r2.Header.Del("Content-Length")
+	r2.GetBody = nil
+
+	res, err := rt.base.RoundTrip(r2)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	plain, _, sender, err := openBody(res.Header, sealed, responseLabel, reqNonce[:], rt.clientPriv)
+	if err != nil {
+		return nil, fmt.Errorf("%v (status %s)", err, res.Status)
+	}
+	if *sender != *rt.serverPub {
+		return nil, errWrongSender
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(plain))
+	res.ContentLength = int64(len(plain))
+	res.Header.Del("Content-Length")
+	return res, nil
+}
+
+// bufferedResponse collects a handler's response so it can be sealed.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// NewHandler returns an http.Handler that opens request bodies sealed by a
+// client created with NewEncryptedHTTPClient, calls h with the plaintext
+// body, and seals h's response for the client. The client's public key is
+// left in the request's X-NaCl-Sender header; since the body was opened with
+// it, h can use it to identify the client.
+//
+// Requests whose bodies cannot be opened get a 400 Bad Request response and
+// are not passed to h. Responses are buffered in memory before they are
+// sealed.
+func NewHandler(h http.Handler, serverPub, serverPriv nacl.Key) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sealed, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		plain, reqNonce, client, err := openBody(r.Header, sealed, requestLabel, nil, serverPriv)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(plain))
+		r.ContentLength = int64(len(plain))
+		r.Header.Del(nonceHeader)
+
+		buf := &bufferedResponse{header: w.Header()}
+		h.ServeHTTP(buf, r)
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+		body, _ := sealBody(w.Header(), buf.body.Bytes(), responseLabel, reqNonce[:], client, serverPub, serverPriv)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(buf.status)
+		w.Write(body)
+	})
+}
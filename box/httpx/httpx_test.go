@@ -0,0 +1,205 @@
+package httpx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/nacl/box"
+)
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("X-Client", r.Header.Get(senderHeader))
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "%s %s", r.Method, body)
+}
+
+func TestEncryptedHTTPClient(t *testing.T) {
+	serverPub, serverPriv, _ := box.GenerateKey(rand.Reader)
+	clientPub, clientPriv, _ := box.GenerateKey(rand.Reader)
+
+	var seen []byte
+	handler := NewHandler(http.HandlerFunc(echoHandler), serverPub, serverPriv)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(seen))
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	client := NewEncryptedHTTPClient(serverPub, clientPub, clientPriv)
+	res, err := client.Post(srv.URL, "text/plain", strings.NewReader("secret request"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(body), "POST secret request"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("got status %d, want %d", res.StatusCode, http.StatusCreated)
+	}
+	if got, want := res.Header.Get("X-Client"), hex.EncodeToString(clientPub[:]); got != want {
+		t.Errorf("handler saw client %q, want %q", got, want)
+	}
+	if bytes.Contains(seen, []byte("secret request")) {
+		t.Errorf("request body was sent in the clear")
+	}
+
+	res, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if got, want := string(body), "GET "; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+}
+
+func TestEncryptedHTTPClientWrongServer(t *testing.T) {
+	serverPub, _, _ := box.GenerateKey(rand.Reader)
+	otherPub, otherPriv, _ := box.GenerateKey(rand.Reader)
+	clientPub, clientPriv, _ := box.GenerateKey(rand.Reader)
+
+	// The server does not have the private key for serverPub, so it cannot
+	// open the request.
+	srv := httptest.NewServer(NewHandler(http.HandlerFunc(echoHandler), otherPub, otherPriv))
+	defer srv.Close()
+	client := NewEncryptedHTTPClient(serverPub, clientPub, clientPriv)
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Errorf("expected error from server without the key")
+	}
+
+	// A response sealed by someone other than the server is rejected.
+	srv2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqNonce, _ := hex.DecodeString(r.Header.Get(nonceHeader))
+		body, _ := sealBody(w.Header(), []byte("forged"), responseLabel, reqNonce, clientPub, otherPub, otherPriv)
+		w.Write(body)
+	}))
+	defer srv2.Close()
+	if _, err := client.Get(srv2.URL); err == nil || !strings.Contains(err.Error(), errWrongSender.Error()) {
+		t.Errorf("got error %v, want %v", err, errWrongSender)
+	}
+
+	// An unencrypted response is rejected.
+	srv3 := httptest.NewServer(http.HandlerFunc(echoHandler))
+	defer srv3.Close()
+	if _, err := client.Get(srv3.URL); err == nil {
+		t.Errorf("expected error from unencrypted response")
+	}
+}
+
+func TestEncryptedHTTPClientRejectsReflectedResponse(t *testing.T) {
+	serverPub, _, _ := box.GenerateKey(rand.Reader)
+	clientPub, clientPriv, _ := box.GenerateKey(rand.Reader)
+
+	// Send the client's own sealed request back, claiming it came from the
+	// server.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set(nonceHeader, r.Header.Get(nonceHeader))
+		w.Header().Set(senderHeader, hex.EncodeToString(serverPub[:]))
+		w.Write(body)
+	}))
+	defer srv.Close()
+	client := NewEncryptedHTTPClient(serverPub, clientPub, clientPriv)
+	if _, err := client.Post(srv.URL, "text/plain", strings.NewReader("reflect me")); err == nil {
+		t.Errorf("expected error from reflected request")
+	}
+}
+
+func TestEncryptedHTTPClientRejectsReplayedResponse(t *testing.T) {
+	serverPub, serverPriv, _ := box.GenerateKey(rand.Reader)
+	clientPub, clientPriv, _ := box.GenerateKey(rand.Reader)
+
+	// Record the first genuine response and serve it for every later request.
+	handler := NewHandler(http.HandlerFunc(echoHandler), serverPub, serverPriv)
+	var recorded *httptest.ResponseRecorder
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if recorded == nil {
+			recorded = httptest.NewRecorder()
+			handler.ServeHTTP(recorded, r)
+		}
+		for k, v := range recorded.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(recorded.Code)
+		w.Write(recorded.Body.Bytes())
+	}))
+	defer srv.Close()
+
+	client := NewEncryptedHTTPClient(serverPub, clientPub, clientPriv)
+	res, err := client.Post(srv.URL, "text/plain", strings.NewReader("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if _, err := client.Post(srv.URL, "text/plain", strings.NewReader("second")); err == nil {
+		t.Errorf("expected error from replayed response")
+	}
+}
+
+func TestHandlerRejectsReflectedResponse(t *testing.T) {
+	serverPub, serverPriv, _ := box.GenerateKey(rand.Reader)
+	clientPub, _, _ := box.GenerateKey(rand.Reader)
+	called := false
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}), serverPub, serverPriv)
+
+	// A response the server sealed for the client is not a valid request.
+	hdr := make(http.Header)
+	sealed, _ := sealBody(hdr, []byte("response"), responseLabel, nil, clientPub, serverPub, serverPriv)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(sealed))
+	req.Header.Set(nonceHeader, hdr.Get(nonceHeader))
+	req.Header.Set(senderHeader, hex.EncodeToString(clientPub[:]))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if called {
+		t.Errorf("handler was called with a reflected response")
+	}
+}
+
+func TestHandlerRejectsTampering(t *testing.T) {
+	serverPub, serverPriv, _ := box.GenerateKey(rand.Reader)
+	clientPub, clientPriv, _ := box.GenerateKey(rand.Reader)
+	called := false
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}), serverPub, serverPriv)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	sealed, _ := sealBody(req.Header, []byte("hello"), requestLabel, nil, serverPub, clientPub, clientPriv)
+	sealed[len(sealed)-1] ^= 1
+	req.Body = ioutil.NopCloser(bytes.NewReader(sealed))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if called {
+		t.Errorf("handler was called with a tampered body")
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("plain"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for unsealed body, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,52 @@
+package box
+
+import (
+	"strings"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// identityKey derives the key for identity boxes from the shared key between
+// peersPublicKey and privateKey, so that an identity box is never sealed
+// under the key EasySeal and EasyOpen use.
+func identityKey(peersPublicKey, privateKey nacl.Key) nacl.Key {
+	return auth.Sum([]byte("nacl box identity"), Precompute(peersPublicKey, privateKey))
+}
+
+// SealToIdentity encrypts message for recipientPublic, as EasySeal does, and
+// binds recipientID, a name for the intended recipient such as an email
+// address, to the box as associated data. The box only opens with
+// OpenAsIdentity and the same recipientID. The output will have a randomly
+// generated nonce prepended to it, and will be Overhead + 24 bytes longer
+// than message.
+//
+// The box keys already bind the box to the recipient's key. Binding the
+// identity as well matters when one key serves several identities, such as
+// the mailboxes on a server: a box addressed to one identity cannot be
+// delivered as if it were addressed to another.
+func SealToIdentity(message []byte, recipientPublic, senderPrivate nacl.Key, recipientID string) ([]byte, error) {
+	nonce := nacl.NewNonce()
+	sealed, err := secretbox.SealWithADReader(message, strings.NewReader(recipientID), nonce, identityKey(recipientPublic, senderPrivate))
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce[:], sealed...), nil
+}
+
+// OpenAsIdentity decrypts a box produced by SealToIdentity, and checks that
+// it was addressed to recipientID. It returns an error if the box cannot be
+// opened, or was addressed to a different identity.
+func OpenAsIdentity(box []byte, senderPublic, recipientPrivate nacl.Key, recipientID string) ([]byte, error) {
+	if len(box) < 24 {
+		return nil, errInvalidInput
+	}
+	nonce := new([24]byte)
+	copy(nonce[:], box)
+	message, err := secretbox.OpenWithADReader(box[24:], strings.NewReader(recipientID), nonce, identityKey(senderPublic, recipientPrivate))
+	if err != nil {
+		return nil, errInvalidInput
+	}
+	return message, nil
+}
@@ -0,0 +1,74 @@
+package box
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestSealToIdentity(t *testing.T) {
+	senderPub, senderPriv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientPub, recipientPriv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("meet at noon")
+	sealed, err := SealToIdentity(message, recipientPub, senderPriv, "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != len(message)+Overhead+24 {
+		t.Errorf("got box length %d, want %d", len(sealed), len(message)+Overhead+24)
+	}
+	opened, err := OpenAsIdentity(sealed, senderPub, recipientPriv, "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opened) != string(message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	_, otherPriv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := append([]byte{}, sealed...)
+	modified[len(modified)-1] ^= 1
+	tests := []struct {
+		name string
+		box  []byte
+		priv [32]byte
+		id   string
+	}{
+		{"other identity", sealed, *recipientPriv, "bob@example.com"},
+		{"empty identity", sealed, *recipientPriv, ""},
+		{"identity prefix", sealed, *recipientPriv, "alice@example.co"},
+		{"wrong key", sealed, *otherPriv, "alice@example.com"},
+		{"modified box", modified, *recipientPriv, "alice@example.com"},
+		{"short box", sealed[:23], *recipientPriv, "alice@example.com"},
+	}
+	for _, tt := range tests {
+		priv := tt.priv
+		if _, err := OpenAsIdentity(tt.box, senderPub, &priv, tt.id); err != errInvalidInput {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, errInvalidInput)
+		}
+	}
+
+	// A plain box of the same message does not open as an identity box.
+	plain := EasySeal(message, recipientPub, senderPriv)
+	if _, err := OpenAsIdentity(plain, senderPub, recipientPriv, ""); err != errInvalidInput {
+		t.Errorf("plain box: got error %v, want %v", err, errInvalidInput)
+	}
+
+	// An identity box, with or without the identity's hash appended, does
+	// not open as a plain box.
+	idHash := sha512.Sum512([]byte("alice@example.com"))
+	for _, b := range [][]byte{sealed, append(append([]byte{}, sealed...), idHash[:]...)} {
+		if _, err := EasyOpen(b, senderPub, recipientPriv); err == nil {
+			t.Errorf("EasyOpen opened an identity box of length %d", len(b))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package box
+
+import (
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/internal/jwk"
+	"github.com/kevinburke/nacl/scalarmult"
+)
+
+// ExportPublicKeyJWK encodes publicKey as a JSON Web Key, as described in
+// RFC 8037: {"kty":"OKP","crv":"X25519","x":"<base64url public key>"}. This
+// lets keys be exchanged with JWK libraries in other languages.
+func ExportPublicKeyJWK(publicKey nacl.Key) ([]byte, error) {
+	return jwk.Marshal("X25519", publicKey[:], nil)
+}
+
+// ExportPrivateKeyJWK encodes privateKey, along with its public key, as a JSON
+// Web Key, as described in RFC 8037.
+func ExportPrivateKeyJWK(privateKey nacl.Key) ([]byte, error) {
+	publicKey := scalarmult.Base(privateKey)
+	return jwk.Marshal("X25519", publicKey[:], privateKey[:])
+}
+
+// ImportPublicKeyJWK decodes an X25519 public key from a JSON Web Key.
+func ImportPublicKeyJWK(data []byte) (nacl.Key, error) {
+	x, _, err := jwk.Unmarshal(data, "X25519", false)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := new([32]byte)
+	copy(publicKey[:], x)
+	return publicKey, nil
+}
+
+// ImportPrivateKeyJWK decodes an X25519 private key from a JSON Web Key. It
+// returns an error if the public key in the "x" parameter does not match the
+// private key.
+func ImportPrivateKeyJWK(data []byte) (nacl.Key, error) {
+	x, d, err := jwk.Unmarshal(data, "X25519", true)
+	if err != nil {
+		return nil, err
+	}
+	privateKey := new([32]byte)
+	copy(privateKey[:], d)
+	if !nacl.Verify(scalarmult.Base(privateKey)[:], x) {
+		return nil, errors.New("box: JWK public key does not match private key")
+	}
+	return privateKey, nil
+}
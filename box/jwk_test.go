@@ -0,0 +1,111 @@
+package box
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Key pairs from RFC 7748, Section 6.1, which RFC 8037 uses for its X25519
+// examples.
+var rfc7748KeyPairs = []struct {
+	private, public string
+}{
+	{
+		"77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a",
+		"8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a",
+	},
+	{
+		"5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb",
+		"de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f",
+	},
+}
+
+func b64(t *testing.T, h string) string {
+	t.Helper()
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestJWKVectors(t *testing.T) {
+	for _, kp := range rfc7748KeyPairs {
+		privateJWK := `{"kty":"OKP","crv":"X25519","x":"` + b64(t, kp.public) + `","d":"` + b64(t, kp.private) + `"}`
+		publicJWK := `{"kty":"OKP","crv":"X25519","x":"` + b64(t, kp.public) + `"}`
+
+		priv, err := ImportPrivateKeyJWK([]byte(privateJWK))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(priv[:]); got != kp.private {
+			t.Errorf("got private key %s, want %s", got, kp.private)
+		}
+		pub, err := ImportPublicKeyJWK([]byte(publicJWK))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(pub[:]); got != kp.public {
+			t.Errorf("got public key %s, want %s", got, kp.public)
+		}
+
+		data, err := ExportPrivateKeyJWK(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != privateJWK {
+			t.Errorf("got private JWK\n%s\nwant\n%s", data, privateJWK)
+		}
+		data, err = ExportPublicKeyJWK(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != publicJWK {
+			t.Errorf("got public JWK\n%s\nwant\n%s", data, publicJWK)
+		}
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportPrivateKeyJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, err := ImportPrivateKeyJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *priv2 != *priv {
+		t.Errorf("private key does not round trip")
+	}
+	data, err = ExportPublicKeyJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub2, err := ImportPublicKeyJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *pub2 != *pub {
+		t.Errorf("public key does not round trip")
+	}
+}
+
+func TestImportJWKErrors(t *testing.T) {
+	alice, bob := rfc7748KeyPairs[0], rfc7748KeyPairs[1]
+	mismatched := `{"kty":"OKP","crv":"X25519","x":"` + b64(t, bob.public) + `","d":"` + b64(t, alice.private) + `"}`
+	if _, err := ImportPrivateKeyJWK([]byte(mismatched)); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected mismatched key error, got %v", err)
+	}
+	ed25519JWK := `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	if _, err := ImportPublicKeyJWK([]byte(ed25519JWK)); err == nil || !strings.Contains(err.Error(), "unsupported curve") {
+		t.Errorf("expected unsupported curve error, got %v", err)
+	}
+}
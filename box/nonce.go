@@ -0,0 +1,54 @@
+package box
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/blake2b"
+)
+
+// sharedNonce returns the 24 byte BLAKE2b hash of the smaller of the two
+// public keys, the larger, and extra.
+func sharedNonce(ourPub, theirPub nacl.Key, extra []byte) nacl.Nonce {
+	lo, hi := ourPub, theirPub
+	if bytes.Compare(lo[:], hi[:]) > 0 {
+		lo, hi = hi, lo
+	}
+	h, err := blake2b.New(24, nil)
+	if err != nil {
+		panic(err)
+	}
+	h.Write(lo[:])
+	h.Write(hi[:])
+	h.Write(extra)
+	nonce := new([24]byte)
+	h.Sum(nonce[:0])
+	return nonce
+}
+
+// SharedNonce returns a nonce derived from a pair of public keys, computed as
+// BLAKE2b-192 of the smaller public key followed by the larger. Both parties
+// compute the same nonce, so it does not need to be sent.
+//
+// The nonce is the same in both directions, and the shared key is too, so it
+// may only be used to seal a single message between a pair of keys. Use
+// SharedNonceN to send more than one.
+func SharedNonce(ourPub, theirPub nacl.Key) nacl.Nonce {
+	return sharedNonce(ourPub, theirPub, nil)
+}
+
+// SharedNonceN returns a nonce derived from a pair of public keys and a
+// sequence number, computed as BLAKE2b-192 of the smaller public key, the
+// larger, and the big-endian sequence number. Both parties compute the same
+// nonce for the same sequence number.
+//
+// Sequence numbers are shared by both directions: a sequence number used by
+// one party to seal a message must never be used by the other. For example,
+// the party with the smaller public key could use only odd sequence numbers,
+// and the other only even ones.
+func SharedNonceN(ourPub, theirPub nacl.Key, seqNum uint64) nacl.Nonce {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], seqNum)
+	return sharedNonce(ourPub, theirPub, seq[:])
+}
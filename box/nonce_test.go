@@ -0,0 +1,51 @@
+package box
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSharedNonce(t *testing.T) {
+	alicePub, _, _ := GenerateKey(rand.Reader)
+	bobPub, _, _ := GenerateKey(rand.Reader)
+	if *SharedNonce(alicePub, bobPub) != *SharedNonce(bobPub, alicePub) {
+		t.Error("SharedNonce depends on argument order")
+	}
+	if *SharedNonceN(alicePub, bobPub, 7) != *SharedNonceN(bobPub, alicePub, 7) {
+		t.Error("SharedNonceN depends on argument order")
+	}
+	carolPub, _, _ := GenerateKey(rand.Reader)
+	if *SharedNonce(alicePub, bobPub) == *SharedNonce(alicePub, carolPub) {
+		t.Error("different key pairs got the same nonce")
+	}
+
+	seen := make(map[[24]byte]bool)
+	seen[*SharedNonce(alicePub, bobPub)] = true
+	for i := uint64(0); i < 100; i++ {
+		nonce := SharedNonceN(alicePub, bobPub, i)
+		if seen[*nonce] {
+			t.Fatalf("sequence number %d: repeated nonce", i)
+		}
+		seen[*nonce] = true
+	}
+}
+
+func TestSharedNonceValue(t *testing.T) {
+	lo, err := nacl.Load("0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hi, err := nacl.Load("0000000000000000000000000000000000000000000000000000000000000002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(SharedNonce(hi, lo)[:]), "9eb102fcc85d7e4bfc8d3db527f511095b7816819bbde0ec"; got != want {
+		t.Errorf("got nonce %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(SharedNonceN(hi, lo, 5)[:]), "dc7cf0d2d735d7f5c132dd7b972b2f8005d710c6bc92c043"; got != want {
+		t.Errorf("got nonce %s, want %s", got, want)
+	}
+}
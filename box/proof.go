@@ -0,0 +1,47 @@
+package box
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/sign"
+	"golang.org/x/crypto/ed25519"
+)
+
+// proofContext is prepended to the public key before it is signed, so a
+// proof cannot be mistaken for a signature over any other message.
+const proofContext = "nacl box key proof\x00"
+
+func proofMessage(pub nacl.Key) []byte {
+	return append([]byte(proofContext), pub[:]...)
+}
+
+// GenerateKeyWithProof generates a new public/private key pair, as with
+// GenerateKey, and signs the public key with signingKey. The proof shows that
+// the holder of signingKey chose the box key pair, so a server that already
+// trusts the signing key can accept the box public key with VerifyProof.
+//
+// The proof does not show that the signer holds the private key for pub; a
+// signer could sign someone else's public key. To check that, send a box to
+// pub and ask for its contents.
+func GenerateKeyWithProof(signingKey sign.PrivateKey) (pub, priv nacl.Key, proof [64]byte, err error) {
+	if len(signingKey) != sign.PrivateKeySize {
+		return nil, nil, proof, errors.New("box: bad signing key length")
+	}
+	pub, priv, err = GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, proof, err
+	}
+	copy(proof[:], ed25519.Sign(ed25519.PrivateKey(signingKey), proofMessage(pub)))
+	return pub, priv, proof, nil
+}
+
+// VerifyProof reports whether proof is a valid signature of pub by
+// signingPub, as created by GenerateKeyWithProof.
+func VerifyProof(pub nacl.Key, proof [64]byte, signingPub sign.PublicKey) bool {
+	if len(signingPub) != sign.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(signingPub), proofMessage(pub), proof[:])
+}
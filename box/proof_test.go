@@ -0,0 +1,45 @@
+package box
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl/scalarmult"
+	"github.com/kevinburke/nacl/sign"
+)
+
+func TestGenerateKeyWithProof(t *testing.T) {
+	signingPub, signingKey, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, proof, err := GenerateKeyWithProof(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *scalarmult.Base(priv) != *pub {
+		t.Error("public key does not match private key")
+	}
+	if !VerifyProof(pub, proof, signingPub) {
+		t.Error("could not verify proof")
+	}
+
+	otherPub, _, _ := sign.Keypair(rand.Reader)
+	if VerifyProof(pub, proof, otherPub) {
+		t.Error("verified proof with mismatched signing key")
+	}
+	otherBoxPub, _, _ := GenerateKey(rand.Reader)
+	if VerifyProof(otherBoxPub, proof, signingPub) {
+		t.Error("verified proof for a different box key")
+	}
+	// A plain signature over the public key is not a proof.
+	signed := sign.Sign(pub[:], signingKey)
+	var plain [64]byte
+	copy(plain[:], signed)
+	if VerifyProof(pub, plain, signingPub) {
+		t.Error("verified plain signature as a proof")
+	}
+	if _, _, _, err := GenerateKeyWithProof(signingKey[:32]); err == nil {
+		t.Error("expected error with short signing key")
+	}
+}
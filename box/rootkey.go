@@ -0,0 +1,27 @@
+package box
+
+import (
+	"crypto/sha512"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/hkdf"
+)
+
+// RootKey derives a root key and a chain key from sharedSecret, such as the
+// result of Precompute, for use in a symmetric ratchet. The 64 bytes of
+// output of HKDF with SHA-512, no salt and info are split in two: the first
+// 32 bytes are the root key, and the last 32 are the chain key. Both parties
+// derive the same keys from the same shared secret and info; different info
+// strings produce independent keys.
+func RootKey(sharedSecret nacl.Key, info string) (rootKey, chainKey nacl.Key) {
+	var out [64]byte
+	r := hkdf.New(sha512.New, sharedSecret[:], nil, []byte(info))
+	if _, err := io.ReadFull(r, out[:]); err != nil {
+		panic(err)
+	}
+	rootKey, chainKey = new([32]byte), new([32]byte)
+	copy(rootKey[:], out[:32])
+	copy(chainKey[:], out[32:])
+	return rootKey, chainKey
+}
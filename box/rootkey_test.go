@@ -0,0 +1,43 @@
+package box
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestRootKey(t *testing.T) {
+	alicePub, alicePriv, _ := GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := GenerateKey(rand.Reader)
+	aliceRoot, aliceChain := RootKey(Precompute(bobPub, alicePriv), "example ratchet")
+	bobRoot, bobChain := RootKey(Precompute(alicePub, bobPriv), "example ratchet")
+	if *aliceRoot != *bobRoot {
+		t.Error("parties derived different root keys")
+	}
+	if *aliceChain != *bobChain {
+		t.Error("parties derived different chain keys")
+	}
+	if *aliceRoot == *aliceChain {
+		t.Error("root key equals chain key")
+	}
+	otherRoot, otherChain := RootKey(Precompute(bobPub, alicePriv), "other ratchet")
+	if *otherRoot == *aliceRoot || *otherChain == *aliceChain {
+		t.Error("different info strings produced the same keys")
+	}
+}
+
+func TestRootKeyValue(t *testing.T) {
+	secret, err := nacl.Load("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, chainKey := RootKey(secret, "nacl ratchet")
+	if got, want := hex.EncodeToString(rootKey[:]), "d0bdd5fee5f72a18ea4421d8bfb14efc96910c991b53ff8a2fb508913eb91764"; got != want {
+		t.Errorf("got root key %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(chainKey[:]), "656b8169a70814eb23b66499ade199d6fe762b78f9d4d2e64af4098fc8f70058"; got != want {
+		t.Errorf("got chain key %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package box
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/scalarmult"
+	"github.com/kevinburke/nacl/sign"
+	"golang.org/x/crypto/ed25519"
+)
+
+// signedData returns the data covered by the signature in a signed box: the
+// recipient's public key followed by the message.
+func signedData(recipientPub nacl.Key, message []byte) []byte {
+	data := make([]byte, 0, len(recipientPub)+len(message))
+	data = append(data, recipientPub[:]...)
+	return append(data, message...)
+}
+
+// SealSigned signs message with senderSignPriv, then encrypts the signature
+// followed by message for recipientPub with Seal, and appends the result to
+// out. The output is Overhead+sign.SignatureSize bytes longer than message.
+// The nonce must be unique for each distinct message for a given pair of box
+// keys.
+//
+// The signature covers the recipient's public key as well as the message, so
+// the recipient cannot decrypt the message and re-encrypt it, signature and
+// all, to a third party who would then believe the sender wrote to them.
+//
+// Unlike a plain box, which the recipient could have forged themselves, a
+// signed box is non-repudiable: the recipient can prove to anyone that the
+// sender signed the message, by revealing it along with the signature. The
+// signing key is long-term, so there is no forward secrecy for the signature:
+// a signature that is revealed remains verifiable even after the box keys are
+// destroyed. Nor does the box have forward secrecy; if senderBoxPriv or the
+// recipient's private key is later compromised, the message can be decrypted.
+func SealSigned(out, message []byte, recipientPub nacl.Key, senderSignPriv sign.PrivateKey, nonce nacl.Nonce, senderBoxPriv nacl.Key) []byte {
+	sig := ed25519.Sign(ed25519.PrivateKey(senderSignPriv), signedData(recipientPub, message))
+	signed := make([]byte, 0, len(sig)+len(message))
+	signed = append(signed, sig...)
+	signed = append(signed, message...)
+	return Seal(out, signed, nonce, recipientPub, senderBoxPriv)
+}
+
+// OpenVerify decrypts a box produced by SealSigned, verifies that the message
+// was signed by senderSignPub for the holder of ourBoxPriv, and appends the
+// message to out. It returns false if the box cannot be opened or the
+// signature is invalid, in which case no plaintext is appended.
+func OpenVerify(out, ciphertext []byte, senderSignPub sign.PublicKey, senderBoxPub, ourBoxPriv nacl.Key, nonce nacl.Nonce) ([]byte, bool) {
+	if len(senderSignPub) != sign.PublicKeySize {
+		return nil, false
+	}
+	signed, ok := Open(nil, ciphertext, nonce, senderBoxPub, ourBoxPriv)
+	if !ok || len(signed) < sign.SignatureSize {
+		return nil, false
+	}
+	sig, message := signed[:sign.SignatureSize], signed[sign.SignatureSize:]
+	ourPub := scalarmult.Base(ourBoxPriv)
+	if !ed25519.Verify(ed25519.PublicKey(senderSignPub), signedData(ourPub, message), sig) {
+		return nil, false
+	}
+	return append(out, message...), true
+}
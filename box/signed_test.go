@@ -0,0 +1,67 @@
+package box
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/sign"
+)
+
+func TestSealSigned(t *testing.T) {
+	senderBoxPub, senderBoxPriv, _ := GenerateKey(rand.Reader)
+	recipientPub, recipientPriv, _ := GenerateKey(rand.Reader)
+	signPub, signPriv, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := nacl.NewNonce()
+	message := []byte("I agree to the terms")
+
+	sealed := SealSigned([]byte("prefix"), message, recipientPub, signPriv, nonce, senderBoxPriv)
+	if !bytes.HasPrefix(sealed, []byte("prefix")) {
+		t.Fatal("output does not start with out")
+	}
+	sealed = sealed[len("prefix"):]
+	if len(sealed) != len(message)+Overhead+sign.SignatureSize {
+		t.Errorf("got length %d, want %d", len(sealed), len(message)+Overhead+sign.SignatureSize)
+	}
+	opened, ok := OpenVerify(nil, sealed, signPub, senderBoxPub, recipientPriv, nonce)
+	if !ok {
+		t.Fatal("could not open signed box")
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	otherSignPub, _, _ := sign.Keypair(rand.Reader)
+	if _, ok := OpenVerify(nil, sealed, otherSignPub, senderBoxPub, recipientPriv, nonce); ok {
+		t.Error("verified signature with wrong signing key")
+	}
+	bad := append([]byte{}, sealed...)
+	bad[len(bad)-1] ^= 0x01
+	if _, ok := OpenVerify(nil, bad, signPub, senderBoxPub, recipientPriv, nonce); ok {
+		t.Error("opened modified box")
+	}
+}
+
+func TestSealSignedForwarded(t *testing.T) {
+	senderBoxPub, senderBoxPriv, _ := GenerateKey(rand.Reader)
+	recipientPub, recipientPriv, _ := GenerateKey(rand.Reader)
+	thirdPub, thirdPriv, _ := GenerateKey(rand.Reader)
+	signPub, signPriv, _ := sign.Keypair(rand.Reader)
+	nonce := nacl.NewNonce()
+
+	// The recipient decrypts the signed box and re-encrypts its contents for a
+	// third party, posing as the sender.
+	sealed := SealSigned(nil, []byte("meet at noon"), recipientPub, signPriv, nonce, senderBoxPriv)
+	signed, ok := Open(nil, sealed, nonce, senderBoxPub, recipientPriv)
+	if !ok {
+		t.Fatal("could not open box")
+	}
+	forwarded := Seal(nil, signed, nonce, thirdPub, recipientPriv)
+	if _, ok := OpenVerify(nil, forwarded, signPub, recipientPub, thirdPriv, nonce); ok {
+		t.Error("verified signed box forwarded to a third party")
+	}
+}
@@ -0,0 +1,123 @@
+// Package threshold seals messages that can only be opened with the private
+// keys of two holders together, for dual control of a secret.
+//
+// SealThreshold2 generates an ephemeral Curve25519 key pair and computes a
+// shared secret with each holder's public key. The message is sealed with
+// secretbox under a key derived from both shared secrets with HKDF-SHA-512.
+// To open a box, each holder computes their shared secret with PartialDecrypt,
+// and CombinePartials uses both to open the box. Neither holder's partial,
+// alone, reveals anything about the message.
+//
+// Whoever runs CombinePartials learns the message, and a partial is enough,
+// with the other holder's partial, to open the box, so partials should be
+// sent to the combining party over a confidential channel. A partial is
+// specific to one box, and does not reveal the holder's private key.
+package threshold
+
+import (
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/scalarmult"
+	"github.com/kevinburke/nacl/secretbox"
+	"golang.org/x/crypto/hkdf"
+)
+
+// PartialSize is the size, in bytes, of a partial decryption.
+const PartialSize = 32
+
+// Overhead is the number of bytes of overhead when sealing a message: the
+// ephemeral public key, the nonce and the secretbox tag.
+const Overhead = 32 + 24 + secretbox.Overhead
+
+var (
+	errInvalidInput = errors.New("threshold: Could not decrypt invalid input")
+	errLowOrderKey  = errors.New("threshold: low order public key")
+	errPartial      = errors.New("threshold: invalid partial decryption")
+)
+
+// isZero reports, in constant time, whether k is all zeros.
+func isZero(k nacl.Key) bool {
+	var acc byte
+	for _, b := range k {
+		acc |= b
+	}
+	return acc == 0
+}
+
+// boxKey derives the secretbox key for a box with the given ephemeral public
+// key from the two holders' shared secrets.
+func boxKey(ephemeralPub []byte, partial1, partial2 []byte) nacl.Key {
+	ikm := make([]byte, 0, 2*PartialSize)
+	ikm = append(ikm, partial1...)
+	ikm = append(ikm, partial2...)
+	key := new([32]byte)
+	r := hkdf.New(sha512.New, ikm, ephemeralPub, []byte("nacl threshold 2-of-2"))
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// SealThreshold2 seals message so that it can only be opened with the
+// private keys for both share1Pub and share2Pub. The box is Overhead bytes
+// longer than message. SealThreshold2 returns an error if either public key
+// is a low order point, which would make that holder's share public.
+func SealThreshold2(message []byte, share1Pub, share2Pub nacl.Key) ([]byte, error) {
+	ephemeralPriv := new([32]byte)
+	if _, err := randombytes.Read(ephemeralPriv[:]); err != nil {
+		return nil, err
+	}
+	ephemeralPub := scalarmult.Base(ephemeralPriv)
+	partial1 := scalarmult.Mult(ephemeralPriv, share1Pub)
+	partial2 := scalarmult.Mult(ephemeralPriv, share2Pub)
+	if isZero(partial1) || isZero(partial2) {
+		return nil, errLowOrderKey
+	}
+	key := boxKey(ephemeralPub[:], partial1[:], partial2[:])
+	nonce := nacl.NewNonce()
+
+	out := make([]byte, 0, Overhead+len(message))
+	out = append(out, ephemeralPub[:]...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, message, nonce, key), nil
+}
+
+// PartialDecrypt returns the partial decryption of box by the holder of
+// sharePriv, one of the two private keys box was sealed for. Both holders'
+// partials are needed to open the box with CombinePartials.
+func PartialDecrypt(box []byte, sharePriv nacl.Key) ([]byte, error) {
+	if len(box) < Overhead {
+		return nil, errInvalidInput
+	}
+	ephemeralPub := new([32]byte)
+	copy(ephemeralPub[:], box)
+	partial := scalarmult.Mult(sharePriv, ephemeralPub)
+	if isZero(partial) {
+		return nil, errInvalidInput
+	}
+	return partial[:], nil
+}
+
+// CombinePartials opens box with the partial decryptions, from
+// PartialDecrypt, of the holders of the first and second public keys passed
+// to SealThreshold2, in that order.
+func CombinePartials(box, partial1, partial2 []byte) ([]byte, error) {
+	if len(box) < Overhead {
+		return nil, errInvalidInput
+	}
+	if len(partial1) != PartialSize || len(partial2) != PartialSize {
+		return nil, errPartial
+	}
+	nonce := new([24]byte)
+	copy(nonce[:], box[32:56])
+	key := boxKey(box[:32], partial1, partial2)
+	message, ok := secretbox.Open(nil, box[56:], nonce, key)
+	if !ok {
+		return nil, errInvalidInput
+	}
+	return message, nil
+}
@@ -0,0 +1,100 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/box"
+)
+
+func holders(t *testing.T) (pub1, priv1, pub2, priv2 nacl.Key) {
+	t.Helper()
+	pub1, priv1, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub2, priv2, err = box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub1, priv1, pub2, priv2
+}
+
+func TestThreshold2(t *testing.T) {
+	pub1, priv1, pub2, priv2 := holders(t)
+	message := []byte("vault combination: 12-34-56")
+	sealed, err := SealThreshold2(message, pub1, pub2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != len(message)+Overhead {
+		t.Errorf("got box length %d, want %d", len(sealed), len(message)+Overhead)
+	}
+	partial1, err := PartialDecrypt(sealed, priv1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial2, err := PartialDecrypt(sealed, priv2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := CombinePartials(sealed, partial1, partial2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	if _, err := CombinePartials(sealed, partial2, partial1); err != errInvalidInput {
+		t.Errorf("swapped partials: got error %v, want %v", err, errInvalidInput)
+	}
+	modified := append([]byte{}, sealed...)
+	modified[len(modified)-1] ^= 1
+	if _, err := CombinePartials(modified, partial1, partial2); err != errInvalidInput {
+		t.Errorf("modified box: got error %v, want %v", err, errInvalidInput)
+	}
+	if _, err := CombinePartials(sealed, partial1, partial2[:PartialSize-1]); err != errPartial {
+		t.Errorf("short partial: got error %v, want %v", err, errPartial)
+	}
+	if _, err := CombinePartials(sealed[:Overhead-1], partial1, partial2); err != errInvalidInput {
+		t.Errorf("short box: got error %v, want %v", err, errInvalidInput)
+	}
+	if _, err := PartialDecrypt(sealed[:Overhead-1], priv1); err != errInvalidInput {
+		t.Errorf("short box: got error %v, want %v", err, errInvalidInput)
+	}
+}
+
+func TestOneShareCannotDecrypt(t *testing.T) {
+	pub1, priv1, pub2, _ := holders(t)
+	sealed, err := SealThreshold2([]byte("secret"), pub1, pub2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial1, err := PartialDecrypt(sealed, priv1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong, err := PartialDecrypt(sealed, otherPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, partial2 := range [][]byte{partial1, wrong, make([]byte, PartialSize)} {
+		if _, err := CombinePartials(sealed, partial1, partial2); err != errInvalidInput {
+			t.Errorf("got error %v, want %v", err, errInvalidInput)
+		}
+	}
+}
+
+func TestLowOrderKey(t *testing.T) {
+	pub1, _, _, _ := holders(t)
+	if _, err := SealThreshold2([]byte("secret"), pub1, new([32]byte)); err != errLowOrderKey {
+		t.Errorf("got error %v, want %v", err, errLowOrderKey)
+	}
+}
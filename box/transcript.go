@@ -0,0 +1,26 @@
+package box
+
+import (
+	"crypto/sha512"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeriveWithTranscript derives an encryption key from sharedSecret, such as
+// the result of Precompute, bound to transcript, the messages of the handshake
+// that produced it. The key is computed with HKDF using SHA-512, with
+// sharedSecret as the secret and the SHA-512 hash of transcript as the salt,
+// in the same way that Noise mixes its handshake hash into derived keys. If
+// the parties saw different transcripts, for example because an attacker
+// modified a handshake message, they derive different keys.
+func DeriveWithTranscript(sharedSecret nacl.Key, transcript []byte) nacl.Key {
+	h := sha512.Sum512(transcript)
+	r := hkdf.New(sha512.New, sharedSecret[:], h[:], []byte("nacl box transcript key"))
+	key := new([32]byte)
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		panic(err)
+	}
+	return key
+}
@@ -0,0 +1,35 @@
+package box
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestDeriveWithTranscript(t *testing.T) {
+	alicePub, alicePriv, _ := GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := GenerateKey(rand.Reader)
+	transcript := append(append([]byte{}, alicePub[:]...), bobPub[:]...)
+	aliceKey := DeriveWithTranscript(Precompute(bobPub, alicePriv), transcript)
+	bobKey := DeriveWithTranscript(Precompute(alicePub, bobPriv), transcript)
+	if *aliceKey != *bobKey {
+		t.Fatal("parties derived different keys from the same transcript")
+	}
+
+	shared := Precompute(bobPub, alicePriv)
+	if *DeriveWithTranscript(shared, nil) == *aliceKey {
+		t.Error("empty transcript produced the same key")
+	}
+	for i := range transcript {
+		tampered := append([]byte{}, transcript...)
+		tampered[i] ^= 1
+		if *DeriveWithTranscript(shared, tampered) == *aliceKey {
+			t.Fatalf("transcript modified at byte %d produced the same key", i)
+		}
+	}
+	if *DeriveWithTranscript(shared, transcript[:len(transcript)-1]) == *aliceKey {
+		t.Error("truncated transcript produced the same key")
+	}
+	if *DeriveWithTranscript(shared, transcript) == *shared {
+		t.Error("derived key equals the shared secret")
+	}
+}
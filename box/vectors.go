@@ -0,0 +1,71 @@
+package box
+
+import (
+	"encoding/hex"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/scalarmult"
+	"github.com/kevinburke/nacl/stream"
+)
+
+// A BoxTestVector is a box and the keys, nonce and message that produced it,
+// for checking other implementations of box against this one. Every field is
+// hex encoded. Ciphertext is the output of Seal, without the nonce.
+type BoxTestVector struct {
+	SenderPrivate    string `json:"sender_private"`
+	SenderPublic     string `json:"sender_public"`
+	RecipientPrivate string `json:"recipient_private"`
+	RecipientPublic  string `json:"recipient_public"`
+	Nonce            string `json:"nonce"`
+	Message          string `json:"message"`
+	Ciphertext       string `json:"ciphertext"`
+}
+
+// GenerateTestVectors returns n test vectors, generated deterministically
+// from seed: the same seed always produces the same vectors. The keys,
+// nonces and messages are read from the XSalsa20 keystream for seed and an
+// all-zero nonce, see stream.PRNG. Message lengths start at zero and grow by
+// one for each vector after the first, with every 16th vector 1000 bytes
+// longer, so that messages of more than one Salsa20 block are covered.
+//
+// The private keys are not secret, so never use them for anything but tests.
+func GenerateTestVectors(n int, seed [32]byte) []BoxTestVector {
+	r := stream.PRNG(&seed, new([24]byte))
+	read := func(b []byte) {
+		// A PRNG never returns an error or a short read.
+		io.ReadFull(r, b)
+	}
+	vectors := make([]BoxTestVector, n)
+	for i := range vectors {
+		senderPriv, recipientPriv := new([32]byte), new([32]byte)
+		nonce := new([24]byte)
+		read(senderPriv[:])
+		read(recipientPriv[:])
+		read(nonce[:])
+		size := i
+		if i%16 == 15 {
+			size += 1000
+		}
+		message := make([]byte, size)
+		read(message)
+
+		senderPub := scalarmult.Base(senderPriv)
+		recipientPub := scalarmult.Base(recipientPriv)
+		ciphertext := Seal(nil, message, nonce, recipientPub, senderPriv)
+		vectors[i] = BoxTestVector{
+			SenderPrivate:    hexKey(senderPriv),
+			SenderPublic:     hexKey(senderPub),
+			RecipientPrivate: hexKey(recipientPriv),
+			RecipientPublic:  hexKey(recipientPub),
+			Nonce:            hex.EncodeToString(nonce[:]),
+			Message:          hex.EncodeToString(message),
+			Ciphertext:       hex.EncodeToString(ciphertext),
+		}
+	}
+	return vectors
+}
+
+func hexKey(k nacl.Key) string {
+	return hex.EncodeToString(k[:])
+}
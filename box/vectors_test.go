@@ -0,0 +1,93 @@
+package box
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/scalarmult"
+	xbox "golang.org/x/crypto/nacl/box"
+)
+
+func decodeKey(t *testing.T, s string) nacl.Key {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		t.Fatalf("bad key %q: %v", s, err)
+	}
+	k := new([32]byte)
+	copy(k[:], b)
+	return k
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestGenerateTestVectors(t *testing.T) {
+	var seed [32]byte
+	copy(seed[:], "nacl box test vectors, seed one!")
+	vectors := GenerateTestVectors(20, seed)
+	if len(vectors) != 20 {
+		t.Fatalf("got %d vectors, want 20", len(vectors))
+	}
+	if again := GenerateTestVectors(20, seed); !reflect.DeepEqual(again, vectors) {
+		t.Error("vectors are not deterministic")
+	}
+	if prefix := GenerateTestVectors(5, seed); !reflect.DeepEqual(prefix, vectors[:5]) {
+		t.Error("fewer vectors are not a prefix of more vectors")
+	}
+	var otherSeed [32]byte
+	if other := GenerateTestVectors(1, otherSeed); reflect.DeepEqual(other[0], vectors[0]) {
+		t.Error("different seeds gave the same vectors")
+	}
+
+	for i, v := range vectors {
+		senderPriv := decodeKey(t, v.SenderPrivate)
+		recipientPriv := decodeKey(t, v.RecipientPrivate)
+		if *scalarmult.Base(senderPriv) != *decodeKey(t, v.SenderPublic) {
+			t.Errorf("vector %d: sender public key does not match private key", i)
+		}
+		if *scalarmult.Base(recipientPriv) != *decodeKey(t, v.RecipientPublic) {
+			t.Errorf("vector %d: recipient public key does not match private key", i)
+		}
+		nonce := new([24]byte)
+		copy(nonce[:], decodeHex(t, v.Nonce))
+		message := decodeHex(t, v.Message)
+		ciphertext := decodeHex(t, v.Ciphertext)
+		if len(ciphertext) != len(message)+Overhead {
+			t.Errorf("vector %d: got ciphertext length %d, want %d", i, len(ciphertext), len(message)+Overhead)
+		}
+		opened, ok := Open(nil, ciphertext, nonce, decodeKey(t, v.SenderPublic), recipientPriv)
+		if !ok || !bytes.Equal(opened, message) {
+			t.Errorf("vector %d: ciphertext does not open to message", i)
+		}
+		// Check against an independent implementation.
+		want := xbox.Seal(nil, message, nonce, decodeKey(t, v.RecipientPublic), senderPriv)
+		if !bytes.Equal(ciphertext, want) {
+			t.Errorf("vector %d: ciphertext does not match golang.org/x/crypto/nacl/box", i)
+		}
+	}
+	if got := len(decodeHex(t, vectors[15].Message)); got != 1015 {
+		t.Errorf("vector 15: got %d byte message, want 1015", got)
+	}
+
+	// Pin the output, so that a change to the vectors is noticed.
+	data, err := json.Marshal(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if got, want := hex.EncodeToString(sum[:]), "a21136e333f4c9c142fd22d18ccd152a6499a4e719486cd4125e458e72b20114"; got != want {
+		t.Errorf("SHA-256 of vectors: got %s, want %s", got, want)
+	}
+}
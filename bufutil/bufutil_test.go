@@ -0,0 +1,60 @@
+package bufutil
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAppendBufferSufficientCapacity(t *testing.T) {
+	in := make([]byte, 3, 10)
+	copy(in, "abc")
+	head, tail := AppendBuffer(in, 5)
+	if len(head) != 8 || len(tail) != 5 {
+		t.Fatalf("got lengths %d, %d, want 8, 5", len(head), len(tail))
+	}
+	if &head[0] != &in[0] {
+		t.Error("head does not reuse the input's storage")
+	}
+	if &tail[0] != &head[3] {
+		t.Error("tail does not alias the end of head")
+	}
+	if !bytes.Equal(head[:3], []byte("abc")) {
+		t.Errorf("got prefix %q, want %q", head[:3], "abc")
+	}
+	if allocs := testing.AllocsPerRun(100, func() {
+		AppendBuffer(in, 7)
+	}); allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+func TestAppendBufferInsufficientCapacity(t *testing.T) {
+	in := make([]byte, 3, 4)
+	copy(in, "abc")
+	head, tail := AppendBuffer(in, 5)
+	if len(head) != 8 || len(tail) != 5 {
+		t.Fatalf("got lengths %d, %d, want 8, 5", len(head), len(tail))
+	}
+	if &head[0] == &in[0] {
+		t.Error("head reuses storage that is too small")
+	}
+	if &tail[0] != &head[3] {
+		t.Error("tail does not alias the end of head")
+	}
+	if !bytes.Equal(head[:3], []byte("abc")) {
+		t.Errorf("got prefix %q, want %q", head[:3], "abc")
+	}
+	tail[0] = 'x'
+	if in[:4][3] == 'x' {
+		t.Error("writing to tail modified the input")
+	}
+
+	head, tail = AppendBuffer(nil, 4)
+	if len(head) != 4 || len(tail) != 4 {
+		t.Errorf("AppendBuffer(nil, 4): got lengths %d, %d, want 4, 4", len(head), len(tail))
+	}
+	head, tail = AppendBuffer(in, 0)
+	if len(head) != 3 || len(tail) != 0 {
+		t.Errorf("AppendBuffer(in, 0): got lengths %d, %d, want 3, 0", len(head), len(tail))
+	}
+}
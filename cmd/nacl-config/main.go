@@ -0,0 +1,106 @@
+// The nacl-config binary edits configuration files encrypted with the
+// github.com/kevinburke/nacl/config package.
+//
+// Usage:
+//
+//	nacl-config edit <file>
+//
+// The edit command decrypts the file to a temporary file, opens it in $EDITOR
+// and encrypts the result when the editor exits. If the file does not exist it
+// is created. The key is read from the NACL_CONFIG_KEY environment variable,
+// as a 64 character hex string; you can generate one by running
+// "openssl rand -hex 32".
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/config"
+)
+
+const usage = `usage: nacl-config edit <file>
+
+Decrypt <file>, open it in $EDITOR and encrypt the result. The key is read
+from the NACL_CONFIG_KEY environment variable, as 64 hex characters.
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+	if flag.NArg() != 2 || flag.Arg(0) != "edit" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	key, err := nacl.Load(os.Getenv("NACL_CONFIG_KEY"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nacl-config: could not load key from NACL_CONFIG_KEY: %v\n", err)
+		os.Exit(2)
+	}
+	if err := edit(config.New(flag.Arg(1), key)); err != nil {
+		fmt.Fprintf(os.Stderr, "nacl-config: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func edit(c *config.EncryptedConfig) error {
+	values, err := c.Load()
+	if os.IsNotExist(err) {
+		values = make(map[string]string)
+	} else if err != nil {
+		return err
+	}
+	original, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		return err
+	}
+	original = append(original, '\n')
+
+	f, err := ioutil.TempFile("", "nacl-config-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(original); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("/bin/sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running editor: %v", err)
+	}
+
+	edited, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, original) {
+		fmt.Fprintln(os.Stderr, "nacl-config: no changes made")
+		return nil
+	}
+	updated := make(map[string]string)
+	if err := json.Unmarshal(edited, &updated); err != nil {
+		return errors.New("invalid JSON, changes discarded: " + err.Error())
+	}
+	return c.Save(updated)
+}
@@ -0,0 +1,103 @@
+// Package config loads and saves encrypted configuration files.
+//
+// A configuration is a set of string keys and values, such as API keys or
+// database passwords. It is stored on disk as JSON, encrypted with secretbox.
+// The file consists of a one byte format version, a random 24 byte nonce and
+// the sealed JSON document.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// Version is the version of the file format written by Save.
+const Version = 1
+
+var errInvalidInput = errors.New("config: Could not decrypt invalid input")
+
+// An EncryptedConfig is an encrypted configuration file stored at a path.
+type EncryptedConfig struct {
+	path string
+	key  nacl.Key
+}
+
+// New returns an EncryptedConfig that reads and writes the file at path,
+// encrypting and decrypting it with key.
+func New(path string, key nacl.Key) *EncryptedConfig {
+	return &EncryptedConfig{path: path, key: key}
+}
+
+// Path returns the path of the configuration file.
+func (c *EncryptedConfig) Path() string {
+	return c.path
+}
+
+// Load reads, decrypts and decodes the configuration file. If the file does
+// not exist, the error from os.Open is returned unchanged.
+func (c *EncryptedConfig) Load() (map[string]string, error) {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1+24 {
+		return nil, errors.New("config: file too short")
+	}
+	if data[0] != Version {
+		return nil, fmt.Errorf("config: unknown file version %d", data[0])
+	}
+	nonce := new([24]byte)
+	copy(nonce[:], data[1:25])
+	plaintext, ok := secretbox.Open(nil, data[25:], nonce, c.key)
+	if !ok {
+		return nil, errInvalidInput
+	}
+	config := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Save encodes and encrypts config with a new random nonce, and replaces the
+// configuration file with the result. The file is only readable by the current
+// user.
+func (c *EncryptedConfig) Save(config map[string]string) error {
+	plaintext, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	nonce := nacl.NewNonce()
+	data := make([]byte, 1, 1+24+len(plaintext)+secretbox.Overhead)
+	data[0] = Version
+	data = append(data, nonce[:]...)
+	data = secretbox.Seal(data, plaintext, nonce, c.key)
+
+	// Write to a temporary file and rename it, so a failed write doesn't
+	// destroy the existing configuration.
+	f, err := ioutil.TempFile(filepath.Dir(c.path), "."+filepath.Base(c.path))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package nacl
+
+import "context"
+
+// WithDeadline runs fn in a new goroutine and returns its result, or returns
+// ctx.Err() as soon as ctx is done, for example because its deadline has
+// passed. It is meant for slow key derivation, such as Argon2id, that should
+// not hold up a request past its deadline.
+//
+// Go cannot stop a running goroutine, so when WithDeadline returns early, fn
+// keeps running to completion and its CPU time and memory are not reclaimed
+// until it does; its result is then discarded. Under sustained load this can
+// pile up abandoned work faster than it finishes. Where possible, use an fn
+// that watches ctx itself, and limit how many calls run at once.
+func WithDeadline(ctx context.Context, fn func() (Key, error)) (Key, error) {
+	type result struct {
+		key Key
+		err error
+	}
+	// The channel is buffered so fn's goroutine can exit after WithDeadline
+	// has returned.
+	c := make(chan result, 1)
+	go func() {
+		key, err := fn()
+		c <- result{key, err}
+	}()
+	select {
+	case r := <-c:
+		return r.key, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
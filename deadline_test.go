@@ -0,0 +1,64 @@
+package nacl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline(t *testing.T) {
+	want := NewKey()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	key, err := WithDeadline(ctx, func() (Key, error) {
+		return want, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != want {
+		t.Errorf("got key %x, want %x", key, want)
+	}
+
+	errFailed := errors.New("failed")
+	if _, err := WithDeadline(ctx, func() (Key, error) {
+		return nil, errFailed
+	}); err != errFailed {
+		t.Errorf("got error %v, want %v", err, errFailed)
+	}
+}
+
+func TestWithDeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	done := make(chan struct{})
+	key, err := WithDeadline(ctx, func() (Key, error) {
+		defer close(done)
+		<-release
+		return NewKey(), nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if key != nil {
+		t.Errorf("got key %x, want nil", key)
+	}
+	// The abandoned call can still finish without blocking.
+	close(release)
+	<-done
+}
+
+func TestWithDeadlineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	release := make(chan struct{})
+	defer close(release)
+	if _, err := WithDeadline(ctx, func() (Key, error) {
+		<-release
+		return NewKey(), nil
+	}); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,116 @@
+// Package env stores secret values in environment variables and .env files,
+// encrypted with secretbox.
+//
+// An encrypted value is the base64 encoding (URL-safe, without padding) of a
+// random nonce followed by the sealed value, so it can be written to a .env
+// file or shell without quoting. Each value is sealed with a subkey derived
+// from the key and the variable's name, so a value cannot be moved to a
+// different variable.
+//
+// Variable names must be made up of ASCII letters, digits and underscores,
+// and must not start with a digit, so that a name cannot add lines or
+// assignments to a .env file.
+package env
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+var (
+	errInvalidInput = errors.New("env: Could not decrypt invalid input")
+	errInvalidName  = errors.New("env: invalid environment variable name")
+)
+
+// An EncryptedEnv reads and writes encrypted environment variables.
+type EncryptedEnv struct {
+	key nacl.Key
+}
+
+// NewEncryptedEnv returns an EncryptedEnv that encrypts and decrypts values
+// with key.
+func NewEncryptedEnv(key nacl.Key) *EncryptedEnv {
+	return &EncryptedEnv{key: key}
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_', 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (e *EncryptedEnv) subkey(name string) nacl.Key {
+	return auth.Sum([]byte(name), e.key)
+}
+
+// Encrypt returns the encrypted form of value for the variable named name.
+func (e *EncryptedEnv) Encrypt(name, value string) (string, error) {
+	if !validName(name) {
+		return "", errInvalidName
+	}
+	sealed := secretbox.EasySeal([]byte(value), e.subkey(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts encrypted, the encrypted form of a value for the variable
+// named name.
+func (e *EncryptedEnv) Decrypt(name, encrypted string) (string, error) {
+	if !validName(name) {
+		return "", errInvalidName
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", errInvalidInput
+	}
+	value, err := secretbox.EasyOpen(sealed, e.subkey(name))
+	if err != nil {
+		return "", errInvalidInput
+	}
+	return string(value), nil
+}
+
+// Get reads the environment variable named by name and decrypts it. If the
+// variable is not set, Get returns an error.
+func (e *EncryptedEnv) Get(name string) (string, error) {
+	encrypted, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("env: %s is not set", name)
+	}
+	return e.Decrypt(name, encrypted)
+}
+
+// Set encrypts value and sets the environment variable named by name to the
+// result.
+func (e *EncryptedEnv) Set(name, value string) error {
+	encrypted, err := e.Encrypt(name, value)
+	if err != nil {
+		return err
+	}
+	return os.Setenv(name, encrypted)
+}
+
+// Put encrypts value and returns a "NAME=ENCRYPTED_VALUE" line, without a
+// trailing newline, for writing to a .env file.
+func (e *EncryptedEnv) Put(name, value string) (envLine string, err error) {
+	encrypted, err := e.Encrypt(name, value)
+	if err != nil {
+		return "", err
+	}
+	return name + "=" + encrypted, nil
+}
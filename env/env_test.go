@@ -0,0 +1,82 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSetGet(t *testing.T) {
+	e := NewEncryptedEnv(nacl.NewKey())
+	const name = "NACL_ENV_TEST_SECRET"
+	defer os.Unsetenv(name)
+	if err := e.Set(name, "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if raw := os.Getenv(name); raw == "" || strings.Contains(raw, "hunter2") {
+		t.Errorf("environment variable was not encrypted: %q", raw)
+	}
+	value, err := e.Get(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "hunter2" {
+		t.Errorf("got %q, want %q", value, "hunter2")
+	}
+
+	if _, err := NewEncryptedEnv(nacl.NewKey()).Get(name); err != errInvalidInput {
+		t.Errorf("expected invalid input error with wrong key, got %v", err)
+	}
+	os.Setenv(name, "not base64!")
+	if _, err := e.Get(name); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+	os.Unsetenv(name)
+	if _, err := e.Get(name); err == nil {
+		t.Error("expected error for unset variable")
+	}
+}
+
+func TestPut(t *testing.T) {
+	e := NewEncryptedEnv(nacl.NewKey())
+	line, err := e.Put("DATABASE_PASSWORD", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 || parts[0] != "DATABASE_PASSWORD" {
+		t.Fatalf("bad env line %q", line)
+	}
+	if strings.ContainsAny(parts[1], "=+/ \n") {
+		t.Errorf("encrypted value %q needs quoting", parts[1])
+	}
+	value, err := e.Decrypt("DATABASE_PASSWORD", parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "correct horse battery staple" {
+		t.Errorf("got %q, want %q", value, "correct horse battery staple")
+	}
+	if _, err := e.Decrypt("API_KEY", parts[1]); err != errInvalidInput {
+		t.Errorf("expected invalid input error for renamed variable, got %v", err)
+	}
+}
+
+func TestInvalidName(t *testing.T) {
+	e := NewEncryptedEnv(nacl.NewKey())
+	for _, name := range []string{"", "A=B", "A\x00B", "A\nB=evil", "A\rB", "A B", "1A", "A-B", "Ä"} {
+		if _, err := e.Put(name, "value"); err != errInvalidName {
+			t.Errorf("%q: expected invalid name error, got %v", name, err)
+		}
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"A", "_", "API_KEY", "api_key2", "_1"} {
+		if !validName(name) {
+			t.Errorf("%q: rejected valid name", name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package envelope defines a canonical wire encoding for a signed secretbox,
+// so that programs in other languages can produce and consume the same bytes.
+//
+// Version 1 of the encoding is, byte by byte:
+//
+//	offset      length  contents
+//	0           1       version, 0x01
+//	1           24      nonce
+//	25          n       ciphertext: the output of crypto_secretbox_easy /
+//	                    secretbox.Seal, a 16 byte Poly1305 tag followed by the
+//	                    encrypted message, so n >= 16
+//	25+n        64      Ed25519 signature (RFC 8032, pure Ed25519) of bytes 0
+//	                    through 25+n-1, that is, of the version, nonce and
+//	                    ciphertext
+//
+// There are no length fields: the ciphertext is everything between the nonce
+// and the final 64 bytes. The signature covers the version byte, so an
+// envelope cannot be reinterpreted as a later version.
+package envelope
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+	"github.com/kevinburke/nacl/sign"
+	"golang.org/x/crypto/ed25519"
+)
+
+// Version is the version of the encoding produced by MarshalSigned.
+const Version = 1
+
+// Overhead is the number of bytes an envelope adds to a ciphertext.
+const Overhead = 1 + 24 + sign.SignatureSize
+
+var (
+	errTooShort  = errors.New("envelope: envelope too short")
+	errSignature = errors.New("envelope: invalid signature")
+)
+
+// MarshalSigned returns the version 1 encoding of nonce and ciphertext,
+// signed with privateKey. ciphertext should be the output of secretbox.Seal
+// with nonce. MarshalSigned panics if ciphertext is shorter than
+// secretbox.Overhead, or privateKey is not sign.PrivateKeySize bytes long.
+func MarshalSigned(nonce nacl.Nonce, ciphertext []byte, privateKey sign.PrivateKey) []byte {
+	if len(ciphertext) < secretbox.Overhead {
+		panic("envelope: ciphertext too short")
+	}
+	out := make([]byte, 0, Overhead+len(ciphertext))
+	out = append(out, Version)
+	out = append(out, nonce[:]...)
+	out = append(out, ciphertext...)
+	sig := ed25519.Sign(ed25519.PrivateKey(privateKey), out)
+	return append(out, sig...)
+}
+
+// UnmarshalSigned checks that envelope is a version 1 envelope signed by
+// publicKey, and returns the nonce and ciphertext in it. The ciphertext
+// still has to be opened with secretbox.Open. UnmarshalSigned panics if
+// publicKey is not sign.PublicKeySize bytes long.
+func UnmarshalSigned(envelope []byte, publicKey sign.PublicKey) (nonce nacl.Nonce, ciphertext []byte, err error) {
+	if len(publicKey) != sign.PublicKeySize {
+		panic("envelope: bad public key length")
+	}
+	if len(envelope) < 1 {
+		return nil, nil, errTooShort
+	}
+	if envelope[0] != Version {
+		return nil, nil, fmt.Errorf("envelope: unsupported version %d", envelope[0])
+	}
+	if len(envelope) < Overhead+secretbox.Overhead {
+		return nil, nil, errTooShort
+	}
+	signed := envelope[:len(envelope)-sign.SignatureSize]
+	sig := envelope[len(envelope)-sign.SignatureSize:]
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), signed, sig) {
+		return nil, nil, errSignature
+	}
+	nonce = new([24]byte)
+	copy(nonce[:], signed[1:25])
+	return nonce, signed[25:len(signed):len(signed)], nil
+}
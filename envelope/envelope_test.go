@@ -0,0 +1,113 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+	"github.com/kevinburke/nacl/sign"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenInputs returns fixed inputs for the golden file test. Ed25519
+// signatures are deterministic, so the envelope is too.
+func goldenInputs() (nonce nacl.Nonce, ciphertext []byte, pub sign.PublicKey, priv sign.PrivateKey) {
+	nonce = new([24]byte)
+	key := new([32]byte)
+	var seed [sign.SeedSize]byte
+	for i := range nonce {
+		nonce[i] = byte(i)
+	}
+	for i := range key {
+		key[i] = byte(0x40 + i)
+		seed[i] = byte(0x80 + i)
+	}
+	pub, priv = sign.KeyPairFromSeed(seed)
+	ciphertext = secretbox.Seal(nil, []byte("hello, world"), nonce, key)
+	return nonce, ciphertext, pub, priv
+}
+
+func TestGolden(t *testing.T) {
+	nonce, ciphertext, pub, priv := goldenInputs()
+	got := MarshalSigned(nonce, ciphertext, priv)
+	golden := filepath.Join("testdata", "signed_v1.hex")
+	if *update {
+		if err := ioutil.WriteFile(golden, []byte(hex.EncodeToString(got)+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("envelope does not match %s; the encoding has changed\ngot  %x\nwant %x", golden, got, want)
+	}
+
+	gotNonce, gotCiphertext, err := UnmarshalSigned(want, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *gotNonce != *nonce || !bytes.Equal(gotCiphertext, ciphertext) {
+		t.Error("golden envelope did not unmarshal to the original inputs")
+	}
+}
+
+func TestUnmarshalSigned(t *testing.T) {
+	pub, priv, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, nonce := nacl.NewKey(), nacl.NewNonce()
+	ciphertext := secretbox.Seal(nil, []byte("message"), nonce, key)
+	env := MarshalSigned(nonce, ciphertext, priv)
+	if len(env) != Overhead+len(ciphertext) {
+		t.Errorf("got envelope length %d, want %d", len(env), Overhead+len(ciphertext))
+	}
+
+	gotNonce, gotCiphertext, err := UnmarshalSigned(env, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, ok := secretbox.Open(nil, gotCiphertext, gotNonce, key)
+	if !ok || string(message) != "message" {
+		t.Errorf("got %q, %t, want %q", message, ok, "message")
+	}
+
+	for i := range env {
+		modified := append([]byte{}, env...)
+		modified[i] ^= 1
+		if _, _, err := UnmarshalSigned(modified, pub); err == nil {
+			t.Fatalf("accepted envelope modified at byte %d", i)
+		}
+	}
+	if _, _, err := UnmarshalSigned(env, otherPub); err != errSignature {
+		t.Errorf("wrong key: got error %v, want %v", err, errSignature)
+	}
+	if _, _, err := UnmarshalSigned(env[:Overhead+secretbox.Overhead-1], pub); err != errTooShort {
+		t.Errorf("short envelope: got error %v, want %v", err, errTooShort)
+	}
+	if _, _, err := UnmarshalSigned(nil, pub); err != errTooShort {
+		t.Errorf("empty envelope: got error %v, want %v", err, errTooShort)
+	}
+	v2 := append([]byte{2}, env[1:]...)
+	if _, _, err := UnmarshalSigned(v2, pub); err == nil || err.Error() != "envelope: unsupported version 2" {
+		t.Errorf("got error %v, want unsupported version", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package fileops operates on directories of sealed files. A sealed file
+// holds the output of secretbox.EasySeal: a 24 byte nonce followed by the
+// box.
+package fileops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// VerifyDir walks the directory tree rooted at root and checks that every
+// regular file is a sealed file that authenticates under key. Files are read
+// and verified concurrently by up to workers goroutines; if workers is not
+// positive, GOMAXPROCS goroutines are used. Plaintext is only held in memory
+// and is never written out.
+//
+// VerifyDir returns the paths, sorted and including root, of the files that
+// failed verification. If part of the tree cannot be walked or a file cannot
+// be read, VerifyDir skips it, verifies the remaining files, and returns the
+// failures along with the first such error.
+func VerifyDir(root string, key nacl.Key, workers int) ([]string, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	paths := make(chan string)
+	var (
+		mu       sync.Mutex
+		failed   []string
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				data, err := ioutil.ReadFile(path)
+				if err != nil {
+					setErr(err)
+					continue
+				}
+				if _, err := secretbox.EasyOpen(data, key); err != nil {
+					mu.Lock()
+					failed = append(failed, path)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			setErr(err)
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			paths <- path
+		}
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+	sort.Strings(failed)
+	return failed, firstErr
+}
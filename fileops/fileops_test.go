@@ -0,0 +1,110 @@
+package fileops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "nacl-fileops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyDir(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	key := nacl.NewKey()
+
+	for _, name := range []string{"a", "b", "sub/c", "sub/deeper/d", "empty"} {
+		writeFile(t, filepath.Join(dir, name), secretbox.EasySeal([]byte("contents of "+name), key))
+	}
+	flipped := secretbox.EasySeal([]byte("flipped"), key)
+	flipped[len(flipped)-1] ^= 1
+	writeFile(t, filepath.Join(dir, "sub/flipped"), flipped)
+	writeFile(t, filepath.Join(dir, "short"), []byte("short"))
+	writeFile(t, filepath.Join(dir, "sub/otherkey"), secretbox.EasySeal([]byte("other"), nacl.NewKey()))
+	truncated := secretbox.EasySeal([]byte("truncated"), key)
+	writeFile(t, filepath.Join(dir, "truncated"), truncated[:len(truncated)-1])
+
+	want := []string{
+		filepath.Join(dir, "short"),
+		filepath.Join(dir, "sub/flipped"),
+		filepath.Join(dir, "sub/otherkey"),
+		filepath.Join(dir, "truncated"),
+	}
+	for _, workers := range []int{0, 1, 3, 100} {
+		failed, err := VerifyDir(dir, key, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(failed, want) {
+			t.Errorf("workers=%d: got failures %q, want %q", workers, failed, want)
+		}
+	}
+}
+
+func TestVerifyDirValid(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	key := nacl.NewKey()
+	writeFile(t, filepath.Join(dir, "a"), secretbox.EasySeal([]byte("a"), key))
+	failed, err := VerifyDir(dir, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 0 {
+		t.Errorf("got failures %q, want none", failed)
+	}
+}
+
+func TestVerifyDirMissing(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	if _, err := VerifyDir(filepath.Join(dir, "missing"), nacl.NewKey(), 2); err == nil {
+		t.Errorf("expected error for missing directory")
+	}
+}
+
+func TestVerifyDirUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	key := nacl.NewKey()
+	writeFile(t, filepath.Join(dir, "locked/a"), secretbox.EasySeal([]byte("a"), key))
+	writeFile(t, filepath.Join(dir, "z/short"), []byte("short"))
+	if err := os.Chmod(filepath.Join(dir, "locked"), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(filepath.Join(dir, "locked"), 0700)
+
+	// The walk continues past the unreadable directory.
+	failed, err := VerifyDir(dir, key, 2)
+	if err == nil {
+		t.Error("expected error for unreadable directory")
+	}
+	if want := []string{filepath.Join(dir, "z/short")}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("got failures %q, want %q", failed, want)
+	}
+}
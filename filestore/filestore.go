@@ -0,0 +1,197 @@
+// Package filestore stores secrets as encrypted files in a directory.
+//
+// Each file is named with the hex SHA-256 hash of the secret's name, so the
+// directory listing does not reveal names. Its contents are sealed with a key
+// derived from the master key and the name with HKDF, so a file cannot be
+// renamed to stand in for another secret. The name itself is also stored in
+// the file, sealed with a separate key derived from the master key, so the
+// names can be listed.
+//
+// A file consists of a random 24 byte nonce, the two byte big-endian length
+// of the sealed name, the sealed name and the sealed data.
+package filestore
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+	"golang.org/x/crypto/hkdf"
+)
+
+// MaxNameLength is the maximum length of a secret's name, in bytes.
+const MaxNameLength = 1<<16 - 1 - secretbox.Overhead
+
+var (
+	errInvalidInput = errors.New("filestore: Could not decrypt invalid input")
+	errInvalidName  = errors.New("filestore: invalid name")
+)
+
+// An EncryptedFileStore reads and writes encrypted files in a directory.
+type EncryptedFileStore struct {
+	dir       string
+	masterKey nacl.Key
+}
+
+// NewEncryptedFileStore returns an EncryptedFileStore for the files in dir,
+// which must already exist, encrypted with keys derived from masterKey.
+func NewEncryptedFileStore(dir string, masterKey nacl.Key) *EncryptedFileStore {
+	return &EncryptedFileStore{dir: dir, masterKey: masterKey}
+}
+
+// deriveKey derives a key from the master key with HKDF-SHA-512, using info.
+func (s *EncryptedFileStore) deriveKey(info []byte) nacl.Key {
+	key := new([32]byte)
+	if _, err := io.ReadFull(hkdf.New(sha512.New, s.masterKey[:], nil, info), key[:]); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// fileKey derives the key for the data stored under name. The prefix keeps
+// file keys distinct from the name key.
+func (s *EncryptedFileStore) fileKey(name string) nacl.Key {
+	return s.deriveKey(append([]byte("file\x00"), name...))
+}
+
+func (s *EncryptedFileStore) nameKey() nacl.Key {
+	return s.deriveKey([]byte("names"))
+}
+
+func zero(key nacl.Key) {
+	for i := range key {
+		key[i] = 0
+	}
+}
+
+func fileName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *EncryptedFileStore) path(name string) string {
+	return filepath.Join(s.dir, fileName(name))
+}
+
+// Write encrypts data and stores it under name, replacing any existing data.
+// The file is only readable by the current user.
+func (s *EncryptedFileStore) Write(name string, data []byte) error {
+	if name == "" || len(name) > MaxNameLength {
+		return errInvalidName
+	}
+	nonce := nacl.NewNonce()
+	nameKey, fileKey := s.nameKey(), s.fileKey(name)
+	defer zero(nameKey)
+	defer zero(fileKey)
+
+	out := make([]byte, 24+2, 24+2+len(name)+len(data)+2*secretbox.Overhead)
+	copy(out, nonce[:])
+	binary.BigEndian.PutUint16(out[24:], uint16(len(name)+secretbox.Overhead))
+	out = secretbox.Seal(out, []byte(name), nonce, nameKey)
+	out = secretbox.Seal(out, data, nonce, fileKey)
+
+	// Write to a temporary file and rename it, so a failed write doesn't
+	// destroy the existing data.
+	f, err := ioutil.TempFile(s.dir, ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(out); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), s.path(name)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// parse splits the contents of a file into its nonce, sealed name and sealed
+// data.
+func parse(contents []byte) (nonce nacl.Nonce, sealedName, sealedData []byte, err error) {
+	if len(contents) < 24+2 {
+		return nil, nil, nil, errInvalidInput
+	}
+	nonce = new([24]byte)
+	copy(nonce[:], contents)
+	n := int(binary.BigEndian.Uint16(contents[24:]))
+	contents = contents[24+2:]
+	if len(contents) < n {
+		return nil, nil, nil, errInvalidInput
+	}
+	return nonce, contents[:n], contents[n:], nil
+}
+
+// Read decrypts and returns the data stored under name. If there is no data
+// stored under name, the error from os.Open is returned unchanged.
+func (s *EncryptedFileStore) Read(name string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	nonce, _, sealedData, err := parse(contents)
+	if err != nil {
+		return nil, err
+	}
+	fileKey := s.fileKey(name)
+	defer zero(fileKey)
+	data, ok := secretbox.Open(nil, sealedData, nonce, fileKey)
+	if !ok {
+		return nil, errInvalidInput
+	}
+	return data, nil
+}
+
+// List returns the names of the secrets in the store, in sorted order. Files
+// whose names cannot be decrypted, or that are not stored under the hash of
+// their name, are skipped.
+func (s *EncryptedFileStore) List() ([]string, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	nameKey := s.nameKey()
+	defer zero(nameKey)
+	var names []string
+	for _, fi := range infos {
+		if !fi.Mode().IsRegular() || len(fi.Name()) != 2*sha256.Size {
+			continue
+		}
+		contents, err := ioutil.ReadFile(filepath.Join(s.dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		nonce, sealedName, _, err := parse(contents)
+		if err != nil {
+			continue
+		}
+		name, ok := secretbox.Open(nil, sealedName, nonce, nameKey)
+		if !ok || fileName(string(name)) != fi.Name() {
+			continue
+		}
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes the data stored under name. Keys are derived for each
+// operation and zeroed as soon as it completes, so there is no key to forget.
+func (s *EncryptedFileStore) Delete(name string) error {
+	return os.Remove(s.path(name))
+}
@@ -0,0 +1,150 @@
+package filestore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "nacl-filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReadWrite(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	s := NewEncryptedFileStore(dir, nacl.NewKey())
+	secrets := map[string][]byte{
+		"prod/db_password": []byte("hunter2"),
+		"prod/api_key":     []byte("sk_test_4eC39HqLyjWDarjtT1zdp7dc"),
+		"empty":            {},
+	}
+	for name, data := range secrets {
+		if err := s.Write(name, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range secrets {
+		got, err := s.Read(name)
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%q: got %q, want %q", name, got, data)
+		}
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range infos {
+		if strings.Contains(fi.Name(), "prod") {
+			t.Errorf("file name %q reveals secret name", fi.Name())
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Errorf("%s: got file mode %v, want 0600", fi.Name(), fi.Mode().Perm())
+		}
+	}
+
+	names, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"empty", "prod/api_key", "prod/db_password"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got names %q, want %q", names, want)
+	}
+
+	if err := s.Write("prod/api_key", []byte("rotated")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Read("prod/api_key"); err != nil || string(got) != "rotated" {
+		t.Errorf("got %q, %v after overwrite", got, err)
+	}
+
+	if err := s.Delete("prod/api_key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("prod/api_key"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error after delete, got %v", err)
+	}
+	if err := s.Delete("prod/api_key"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error deleting twice, got %v", err)
+	}
+	names, err = s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"empty", "prod/db_password"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got names %q, want %q", names, want)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	key := nacl.NewKey()
+	s := NewEncryptedFileStore(dir, key)
+	if err := s.Write("a", []byte("secret a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write("b", []byte("secret b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEncryptedFileStore(dir, nacl.NewKey()).Read("a"); err != errInvalidInput {
+		t.Errorf("expected invalid input error with wrong key, got %v", err)
+	}
+
+	// Replace a's file with b's; it must not be readable as a, and must not
+	// be listed twice.
+	contents, err := ioutil.ReadFile(filepath.Join(dir, fileName("b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, fileName("a")), contents, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("a"); err != errInvalidInput {
+		t.Errorf("expected invalid input error for swapped file, got %v", err)
+	}
+	names, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got names %q, want %q", names, want)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, fileName("b")), contents[:20], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("b"); err != errInvalidInput {
+		t.Errorf("expected invalid input error for truncated file, got %v", err)
+	}
+}
+
+func TestInvalidName(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	s := NewEncryptedFileStore(dir, nacl.NewKey())
+	if err := s.Write("", []byte("data")); err != errInvalidName {
+		t.Errorf("expected invalid name error, got %v", err)
+	}
+	if err := s.Write(strings.Repeat("a", MaxNameLength+1), nil); err != errInvalidName {
+		t.Errorf("expected invalid name error, got %v", err)
+	}
+	if err := s.Write(strings.Repeat("a", MaxNameLength), nil); err != nil {
+		t.Errorf("could not write maximum length name: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Package flags defines command line flags whose values are passed
+// encrypted, so that secrets given as flags do not appear in plaintext in
+// shell history or the process's command line.
+//
+// An encrypted value has the same format as in package env: the base64
+// encoding (URL-safe, without padding) of a random nonce followed by the
+// sealed value. Each value is sealed with a subkey derived from the key and
+// the flag's name, so a value cannot be moved to a different flag. The key
+// itself should come from somewhere other than the command line, such as a
+// file or an environment variable.
+package flags
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+var errInvalidInput = errors.New("flags: Could not decrypt invalid input")
+
+// An EncryptedFlagset is a flag.FlagSet that can also define flags whose
+// values are encrypted. All of the flag.FlagSet methods, such as Parse and
+// String, are available on it.
+type EncryptedFlagset struct {
+	*flag.FlagSet
+	key nacl.Key
+}
+
+// NewEncryptedFlagset returns an EncryptedFlagset that decrypts flag values
+// with key. The underlying FlagSet is named after the program, os.Args[0],
+// and exits on errors, like flag.CommandLine.
+func NewEncryptedFlagset(key nacl.Key) *EncryptedFlagset {
+	return &EncryptedFlagset{
+		FlagSet: flag.NewFlagSet(os.Args[0], flag.ExitOnError),
+		key:     key,
+	}
+}
+
+func (f *EncryptedFlagset) subkey(name string) nacl.Key {
+	return auth.Sum([]byte(name), f.key)
+}
+
+// Encrypt returns the encrypted form of value for the flag named name, to
+// pass on the command line.
+func (f *EncryptedFlagset) Encrypt(name, value string) string {
+	sealed := secretbox.EasySeal([]byte(value), f.subkey(name))
+	return base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+func (f *EncryptedFlagset) decrypt(name, encrypted string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", errInvalidInput
+	}
+	value, err := secretbox.EasyOpen(sealed, f.subkey(name))
+	if err != nil {
+		return "", errInvalidInput
+	}
+	return string(value), nil
+}
+
+// encryptedValue is a flag.Value that decrypts the value it is set to.
+type encryptedValue struct {
+	f    *EncryptedFlagset
+	name string
+	p    *string
+}
+
+func (v *encryptedValue) Set(s string) error {
+	value, err := v.f.decrypt(v.name, s)
+	if err != nil {
+		return err
+	}
+	*v.p = value
+	return nil
+}
+
+// String always returns the empty string, so that decrypted values are not
+// shown in usage messages or by callers of Visit.
+func (v *encryptedValue) String() string {
+	return ""
+}
+
+// EncryptedString defines a string flag with the given name, default value
+// and usage string, and returns a pointer to the variable that holds its
+// value. The value on the command line must be encrypted, as returned by
+// Encrypt; the variable holds the decrypted value. The default value is not
+// encrypted, and is not shown in usage messages.
+func (f *EncryptedFlagset) EncryptedString(name, defaultValue, usage string) *string {
+	p := new(string)
+	*p = defaultValue
+	f.Var(&encryptedValue{f: f, name: name, p: p}, name, usage)
+	return p
+}
@@ -0,0 +1,83 @@
+package flags
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func newFlagset(key nacl.Key) *EncryptedFlagset {
+	f := NewEncryptedFlagset(key)
+	f.Init("test", flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	return f
+}
+
+func TestEncryptedString(t *testing.T) {
+	key := nacl.NewKey()
+	f := newFlagset(key)
+	apiKey := f.EncryptedString("api-key", "", "API key")
+	token := f.EncryptedString("token", "default-token", "token")
+	region := f.String("region", "us-east-1", "region")
+
+	encrypted := f.Encrypt("api-key", "sk_test_4eC39HqLyjWDarjtT1zdp7dc")
+	if strings.Contains(encrypted, "sk_test") {
+		t.Fatalf("encrypted value contains plaintext: %q", encrypted)
+	}
+	if err := f.Parse([]string{"-api-key", encrypted, "-region", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if *apiKey != "sk_test_4eC39HqLyjWDarjtT1zdp7dc" {
+		t.Errorf("got api key %q", *apiKey)
+	}
+	if *token != "default-token" {
+		t.Errorf("got token %q, want the default", *token)
+	}
+	if *region != "eu-west-1" {
+		t.Errorf("got region %q, want %q", *region, "eu-west-1")
+	}
+	if got := f.Lookup("api-key").Value.String(); got != "" {
+		t.Errorf("flag value String() revealed %q", got)
+	}
+}
+
+func TestEncryptedStringInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	otherKey := nacl.NewKey()
+	// A value for one flag cannot be used for another.
+	swapped := newFlagset(key).Encrypt("token", "secret")
+	for _, value := range []string{
+		"secret",
+		"not base64!",
+		swapped,
+		NewEncryptedFlagset(otherKey).Encrypt("api-key", "secret"),
+	} {
+		f := newFlagset(key)
+		apiKey := f.EncryptedString("api-key", "", "API key")
+		err := f.Parse([]string{"-api-key", value})
+		if err == nil || !strings.Contains(err.Error(), errInvalidInput.Error()) {
+			t.Errorf("%q: got error %v, want %v", value, err, errInvalidInput)
+		}
+		if *apiKey != "" {
+			t.Errorf("%q: flag set to %q after failed decryption", value, *apiKey)
+		}
+	}
+}
+
+func TestUsageHidesDefault(t *testing.T) {
+	f := newFlagset(nacl.NewKey())
+	f.EncryptedString("api-key", "hunter2", "API key")
+	var buf bytes.Buffer
+	f.SetOutput(&buf)
+	f.PrintDefaults()
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("usage reveals the default value: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "API key") {
+		t.Errorf("usage does not describe the flag: %q", buf.String())
+	}
+}
@@ -0,0 +1,143 @@
+// Package groupkey lets a group of parties agree on a symmetric key that is
+// a combination of random shares from all of them, so no single party chose
+// the key.
+//
+// Each party picks a random 32 byte share. Shares are combined with XOR, and
+// the group key is derived from the result with HKDF-SHA-512. If a party saw
+// the other shares before picking its own, it could pick a share that makes
+// the XOR any value it liked, and so choose the key. To prevent that, the
+// protocol runs in two rounds:
+//
+//  1. Every party calls Commit and sends its commitment, a hash of its share,
+//     to every other party.
+//  2. Once it has every commitment, each party calls Contribute and sends its
+//     share to every other party.
+//
+// Finally, each party calls Finalize with all of the commitments and shares,
+// which checks every share against its commitment.
+//
+// Anyone who sees all of the shares can compute the group key, so shares must
+// be sent over confidential, authenticated channels, for example sealed with
+// box to each other party. This protocol is not a substitute for a
+// multi-party computation: a party that stops after seeing the other shares
+// can prevent the key from being established, and any party can reveal the
+// key once it has it.
+package groupkey
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ShareSize is the size, in bytes, of a share.
+const ShareSize = 32
+
+var (
+	errNotCommitted = errors.New("groupkey: Contribute called before Commit")
+	errCommitted    = errors.New("groupkey: Commit called twice")
+	errMissingShare = errors.New("groupkey: our share is missing")
+)
+
+func commitment(share []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("nacl group key commitment\x00"))
+	h.Write(share)
+	return h.Sum(nil)
+}
+
+// A GroupKeyProtocol is one party's state in a run of the protocol. It is not
+// safe for concurrent use.
+type GroupKeyProtocol struct {
+	n     int
+	share []byte
+}
+
+// NewGroupKeyProtocol returns the state for one party in a group of n
+// parties, including itself. It panics if n is less than 2.
+func NewGroupKeyProtocol(n int) *GroupKeyProtocol {
+	if n < 2 {
+		panic("groupkey: a group needs at least two parties")
+	}
+	return &GroupKeyProtocol{n: n}
+}
+
+// Commit picks this party's random share and returns a commitment to it, to
+// send to every other party before any shares are revealed.
+func (p *GroupKeyProtocol) Commit() ([]byte, error) {
+	if p.share != nil {
+		return nil, errCommitted
+	}
+	share := make([]byte, ShareSize)
+	if _, err := randombytes.Read(share); err != nil {
+		return nil, err
+	}
+	p.share = share
+	return commitment(share), nil
+}
+
+// Contribute returns this party's share, to send to every other party once
+// all of the commitments have been received.
+func (p *GroupKeyProtocol) Contribute() (myShare []byte, err error) {
+	if p.share == nil {
+		return nil, errNotCommitted
+	}
+	return append([]byte(nil), p.share...), nil
+}
+
+// Finalize checks that allShares holds one share per party, including this
+// party's own, each matching the commitment at the same index in
+// allCommitments, and returns the group key derived from them. Every party
+// gets the same key, whatever order the shares are in.
+//
+// Finalize rejects a repeated share or commitment. Equal shares cancel out
+// under XOR, so a copied share would let the key be computed by anyone who
+// saw the others.
+func (p *GroupKeyProtocol) Finalize(allShares, allCommitments [][]byte) (nacl.Key, error) {
+	if p.share == nil {
+		return nil, errNotCommitted
+	}
+	if len(allShares) != p.n || len(allCommitments) != p.n {
+		return nil, fmt.Errorf("groupkey: got %d shares and %d commitments, want %d of each", len(allShares), len(allCommitments), p.n)
+	}
+	var combined [ShareSize]byte
+	found := false
+	seenShares := make(map[string]bool, p.n)
+	seenCommitments := make(map[string]bool, p.n)
+	for i, share := range allShares {
+		if len(share) != ShareSize {
+			return nil, fmt.Errorf("groupkey: share %d has length %d, want %d", i, len(share), ShareSize)
+		}
+		if seenShares[string(share)] || seenCommitments[string(allCommitments[i])] {
+			return nil, fmt.Errorf("groupkey: share %d is a duplicate", i)
+		}
+		seenShares[string(share)] = true
+		seenCommitments[string(allCommitments[i])] = true
+		if subtle.ConstantTimeCompare(commitment(share), allCommitments[i]) != 1 {
+			return nil, fmt.Errorf("groupkey: share %d does not match its commitment", i)
+		}
+		if bytes.Equal(share, p.share) {
+			found = true
+		}
+		for j := range combined {
+			combined[j] ^= share[j]
+		}
+	}
+	if !found {
+		return nil, errMissingShare
+	}
+	key := new([32]byte)
+	r := hkdf.New(sha512.New, combined[:], nil, []byte("nacl group key"))
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
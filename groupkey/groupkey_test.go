@@ -0,0 +1,125 @@
+package groupkey
+
+import (
+	"testing"
+)
+
+// run runs the protocol for n parties and returns each party's state and the
+// messages they exchanged.
+func run(t *testing.T, n int) ([]*GroupKeyProtocol, [][]byte, [][]byte) {
+	t.Helper()
+	parties := make([]*GroupKeyProtocol, n)
+	commitments := make([][]byte, n)
+	shares := make([][]byte, n)
+	for i := range parties {
+		parties[i] = NewGroupKeyProtocol(n)
+		c, err := parties[i].Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments[i] = c
+	}
+	for i, p := range parties {
+		s, err := p.Contribute()
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[i] = s
+	}
+	return parties, commitments, shares
+}
+
+func TestGroupKey(t *testing.T) {
+	for _, n := range []int{2, 3, 10} {
+		parties, commitments, shares := run(t, n)
+		first, err := parties[0].Finalize(shares, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, p := range parties[1:] {
+			key, err := p.Finalize(shares, commitments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *key != *first {
+				t.Errorf("n=%d: party %d derived a different key", n, i+1)
+			}
+		}
+		// Order does not matter, as long as commitments match shares.
+		shares[0], shares[1] = shares[1], shares[0]
+		commitments[0], commitments[1] = commitments[1], commitments[0]
+		key, err := parties[0].Finalize(shares, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *key != *first {
+			t.Errorf("n=%d: reordered shares gave a different key", n)
+		}
+	}
+}
+
+func TestGroupKeyRejectsChangedShare(t *testing.T) {
+	parties, commitments, shares := run(t, 3)
+	// The last party tries to change its share after seeing the others.
+	shares[2] = make([]byte, ShareSize)
+	if _, err := parties[0].Finalize(shares, commitments); err == nil {
+		t.Error("accepted a share that does not match its commitment")
+	}
+}
+
+func TestGroupKeyRejectsDuplicateShare(t *testing.T) {
+	parties, commitments, shares := run(t, 2)
+	// A copy of our own share passes the commitment and ownership checks,
+	// but the two copies cancel out under XOR.
+	dupShares := [][]byte{shares[0], shares[0]}
+	dupCommitments := [][]byte{commitments[0], commitments[0]}
+	if _, err := parties[0].Finalize(dupShares, dupCommitments); err == nil {
+		t.Error("accepted a copied share")
+	}
+
+	parties, commitments, shares = run(t, 3)
+	shares[2], commitments[2] = shares[1], commitments[1]
+	if _, err := parties[0].Finalize(shares, commitments); err == nil {
+		t.Error("accepted a copy of another party's share")
+	}
+}
+
+func TestGroupKeyErrors(t *testing.T) {
+	p := NewGroupKeyProtocol(2)
+	if _, err := p.Contribute(); err != errNotCommitted {
+		t.Errorf("Contribute before Commit: got %v, want %v", err, errNotCommitted)
+	}
+	if _, err := p.Finalize(nil, nil); err != errNotCommitted {
+		t.Errorf("Finalize before Commit: got %v, want %v", err, errNotCommitted)
+	}
+	if _, err := p.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Commit(); err != errCommitted {
+		t.Errorf("second Commit: got %v, want %v", err, errCommitted)
+	}
+
+	parties, commitments, shares := run(t, 3)
+	if _, err := parties[0].Finalize(shares[:2], commitments[:2]); err == nil {
+		t.Error("accepted too few shares")
+	}
+	short := append([][]byte{}, shares...)
+	short[1] = short[1][:ShareSize-1]
+	if _, err := parties[0].Finalize(short, commitments); err == nil {
+		t.Error("accepted a short share")
+	}
+	// Shares from a different run do not include our own share.
+	_, otherCommitments, otherShares := run(t, 3)
+	if _, err := parties[0].Finalize(otherShares, otherCommitments); err != errMissingShare {
+		t.Errorf("got error %v, want %v", err, errMissingShare)
+	}
+}
+
+func TestNewGroupKeyProtocolPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewGroupKeyProtocol(1) did not panic")
+		}
+	}()
+	NewGroupKeyProtocol(1)
+}
@@ -0,0 +1,73 @@
+// Package jwk encodes and decodes Octet Key Pair keys in JSON Web Key format,
+// as described in RFC 7517 and RFC 8037.
+package jwk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size, in bytes, of Ed25519 and X25519 keys.
+const KeySize = 32
+
+type okp struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	D   string `json:"d,omitempty"`
+}
+
+// Marshal returns a JWK with key type "OKP", the curve crv, the public key x
+// and, if it is non-nil, the private key d.
+func Marshal(crv string, x, d []byte) ([]byte, error) {
+	key := okp{
+		Kty: "OKP",
+		Crv: crv,
+		X:   base64.RawURLEncoding.EncodeToString(x),
+	}
+	if d != nil {
+		key.D = base64.RawURLEncoding.EncodeToString(d)
+	}
+	return json.Marshal(key)
+}
+
+func decode(name, s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("jwk: invalid %q parameter: %v", name, err)
+	}
+	if len(b) != KeySize {
+		return nil, fmt.Errorf("jwk: incorrect %q length: %d, should be %d", name, len(b), KeySize)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes an OKP JWK for the curve crv and returns the public key x
+// and, if private is true, the private key d.
+func Unmarshal(data []byte, crv string, private bool) (x, d []byte, err error) {
+	var key okp
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, nil, err
+	}
+	if key.Kty != "OKP" {
+		return nil, nil, fmt.Errorf("jwk: unsupported key type %q", key.Kty)
+	}
+	if key.Crv != crv {
+		return nil, nil, fmt.Errorf("jwk: unsupported curve %q, should be %q", key.Crv, crv)
+	}
+	if x, err = decode("x", key.X); err != nil {
+		return nil, nil, err
+	}
+	if !private {
+		return x, nil, nil
+	}
+	if key.D == "" {
+		return nil, nil, errors.New(`jwk: missing "d" parameter for private key`)
+	}
+	if d, err = decode("d", key.D); err != nil {
+		return nil, nil, err
+	}
+	return x, d, nil
+}
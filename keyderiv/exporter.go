@@ -0,0 +1,30 @@
+package keyderiv
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ExporterSize is the size, in bytes, of the secret accepted by
+// KeyFromExporter.
+const ExporterSize = 64
+
+// KeyFromExporter derives a key from a 64-byte secret that is already
+// uniformly random, such as the output of a TLS exporter
+// (tls.ConnectionState.ExportKeyingMaterial). The key is computed with
+// HKDF-Expand, using SHA-512, exported as the pseudorandom key, and label as
+// the info parameter. Different labels produce independent keys.
+func KeyFromExporter(exported []byte, label string) (nacl.Key, error) {
+	if len(exported) != ExporterSize {
+		return nil, fmt.Errorf("keyderiv: incorrect exporter length: %d, should be %d", len(exported), ExporterSize)
+	}
+	key := new([32]byte)
+	if _, err := io.ReadFull(hkdf.Expand(sha512.New, exported, []byte(label)), key[:]); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
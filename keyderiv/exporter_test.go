@@ -0,0 +1,57 @@
+package keyderiv
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestKeyFromExporter(t *testing.T) {
+	exported := make([]byte, ExporterSize)
+	for i := range exported {
+		exported[i] = byte(i)
+	}
+	key, err := KeyFromExporter(exported, "nacl test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With a single block of output, HKDF-Expand is HMAC(prk, info || 0x01).
+	mac := hmac.New(sha512.New, exported)
+	mac.Write([]byte("nacl test\x01"))
+	if want := mac.Sum(nil)[:32]; string(key[:]) != string(want) {
+		t.Errorf("got key %x, want %x", key, want)
+	}
+
+	again, err := KeyFromExporter(exported, "nacl test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *again != *key {
+		t.Errorf("KeyFromExporter is not deterministic")
+	}
+	other, err := KeyFromExporter(exported, "nacl test 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *other == *key {
+		t.Errorf("different labels produced the same key")
+	}
+	exported[0] ^= 1
+	changed, err := KeyFromExporter(exported, "nacl test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *changed == *key {
+		t.Errorf("different secrets produced the same key")
+	}
+}
+
+func TestKeyFromExporterLength(t *testing.T) {
+	for _, n := range []int{0, 32, 63, 65} {
+		_, err := KeyFromExporter(make([]byte, n), "label")
+		if err == nil {
+			t.Errorf("%d: expected error, got nil", n)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package keyderiv derives keys and password hashes from passwords and other
+// secrets.
+package keyderiv
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevinburke/nacl/randombytes"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// PasswordHashOpsLimit is the number of passes over memory used by
+	// PasswordHashStr. It matches libsodium's
+	// crypto_pwhash_OPSLIMIT_INTERACTIVE.
+	PasswordHashOpsLimit = 2
+
+	// PasswordHashMemLimit is the amount of memory, in bytes, used by
+	// PasswordHashStr. It matches libsodium's
+	// crypto_pwhash_MEMLIMIT_INTERACTIVE.
+	PasswordHashMemLimit = 64 * 1024 * 1024
+)
+
+const (
+	passwordSaltSize = 16
+	passwordHashSize = 32
+	argon2Version    = 19
+)
+
+var errInvalidHash = errors.New("keyderiv: invalid password hash string")
+
+// PasswordHashStr hashes password with Argon2id and a random salt, and returns
+// a string that encodes the algorithm, its parameters, the salt and the hash,
+// for example:
+//
+//	$argon2id$v=19$m=65536,t=2,p=1$<salt>$<hash>
+//
+// The output is compatible with libsodium's crypto_pwhash_str, and is suitable
+// for storing in a database. Use PasswordVerify to check a password against it.
+func PasswordHashStr(password []byte) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := randombytes.Read(salt); err != nil {
+		return "", err
+	}
+	const memory = PasswordHashMemLimit / 1024
+	hash := argon2.IDKey(password, salt, PasswordHashOpsLimit, memory, 1, passwordHashSize)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2Version,
+		memory, PasswordHashOpsLimit, 1,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+type passwordHash struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	hash    []byte
+}
+
+func parsePasswordHash(s string) (*passwordHash, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return nil, errInvalidHash
+	}
+	if parts[2] != fmt.Sprintf("v=%d", argon2Version) {
+		return nil, errInvalidHash
+	}
+	h := new(passwordHash)
+	var threads uint32
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &threads); err != nil {
+		return nil, errInvalidHash
+	}
+	if h.time < 1 || threads < 1 || threads > 255 || h.memory < 8*threads {
+		return nil, errInvalidHash
+	}
+	h.threads = uint8(threads)
+	var err error
+	h.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, errInvalidHash
+	}
+	h.hash, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(h.hash) == 0 {
+		return nil, errInvalidHash
+	}
+	return h, nil
+}
+
+// PasswordVerify reports whether password matches hash, a string produced by
+// PasswordHashStr or by libsodium's crypto_pwhash_str. It returns false if
+// hash is not a valid Argon2id hash string.
+func PasswordVerify(hash string, password []byte) bool {
+	h, err := parsePasswordHash(hash)
+	if err != nil {
+		return false
+	}
+	computed := argon2.IDKey(password, h.salt, h.time, h.memory, h.threads, uint32(len(h.hash)))
+	return subtle.ConstantTimeCompare(computed, h.hash) == 1
+}
@@ -0,0 +1,72 @@
+package keyderiv
+
+import (
+	"strings"
+	"testing"
+)
+
+// These hashes were generated with libsodium's crypto_pwhash_argon2id_str.
+var libsodiumHashes = []string{
+	// crypto_pwhash_OPSLIMIT_INTERACTIVE, crypto_pwhash_MEMLIMIT_INTERACTIVE
+	"$argon2id$v=19$m=65536,t=2,p=1$Q8SonFZghvyd+Yw3K6S+KQ$/Vp8SLMlfXdVbQVrCen/R5bMGmjhsvgpNkPH7sysrR8",
+	// crypto_pwhash_OPSLIMIT_MIN, 64 KiB
+	"$argon2id$v=19$m=64,t=1,p=1$iRqY2OF5n3t4ZatJMyNZDQ$yYVO2fC+NkX0Ckf0bc4BrtZUYcQ/eUVcvFeza5eBxYs",
+}
+
+var password = []byte("correct horse battery staple")
+
+func TestPasswordVerifyLibsodium(t *testing.T) {
+	for _, hash := range libsodiumHashes {
+		if !PasswordVerify(hash, password) {
+			t.Errorf("PasswordVerify(%q): got false, want true", hash)
+		}
+		if PasswordVerify(hash, []byte("incorrect horse battery staple")) {
+			t.Errorf("PasswordVerify(%q) with wrong password: got true, want false", hash)
+		}
+	}
+}
+
+func TestPasswordVerifyTampered(t *testing.T) {
+	hash := libsodiumHashes[1]
+	tampered := []string{
+		strings.Replace(hash, "yYVO2", "yYVO3", 1),
+		strings.Replace(hash, "iRqY2", "iRqY3", 1),
+		strings.Replace(hash, "t=1", "t=2", 1),
+		strings.Replace(hash, "m=64", "m=128", 1),
+		strings.Replace(hash, "v=19", "v=16", 1),
+		strings.Replace(hash, "argon2id", "argon2i", 1),
+		hash[:len(hash)-1],
+		"",
+		"$argon2id$v=19$m=64,t=1,p=1$$",
+		"$argon2id$v=19$m=64,t=0,p=1$iRqY2OF5n3t4ZatJMyNZDQ$yYVO2fC+NkX0Ckf0bc4BrtZUYcQ/eUVcvFeza5eBxYs",
+		"$argon2id$v=19$m=64,t=1,p=0$iRqY2OF5n3t4ZatJMyNZDQ$yYVO2fC+NkX0Ckf0bc4BrtZUYcQ/eUVcvFeza5eBxYs",
+	}
+	for _, hash := range tampered {
+		if PasswordVerify(hash, password) {
+			t.Errorf("PasswordVerify(%q): got true, want false", hash)
+		}
+	}
+}
+
+func TestPasswordHashStr(t *testing.T) {
+	hash, err := PasswordHashStr(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "$argon2id$v=19$m=65536,t=2,p=1$"; !strings.HasPrefix(hash, want) {
+		t.Errorf("got hash %q, want prefix %q", hash, want)
+	}
+	if !PasswordVerify(hash, password) {
+		t.Errorf("could not verify password against %q", hash)
+	}
+	if PasswordVerify(hash, []byte("wrong")) {
+		t.Errorf("verified wrong password against %q", hash)
+	}
+	hash2, err := PasswordHashStr(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == hash2 {
+		t.Errorf("two hashes of the same password should use different salts")
+	}
+}
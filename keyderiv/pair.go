@@ -0,0 +1,38 @@
+package keyderiv
+
+import (
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/argon2"
+)
+
+// MinSaltSize is the smallest salt, in bytes, accepted by
+// DeriveKeyPairFromPassword.
+const MinSaltSize = 16
+
+var errShortSalt = errors.New("keyderiv: salt is too short")
+
+// DeriveKeyPairFromPassword derives two keys from password and salt, for
+// example one for encryption and one for authentication. It computes 64 bytes
+// of Argon2id output, with the same parameters as PasswordHashStr, and splits
+// them in two: the first 32 bytes are encKey and the last 32 are authKey.
+// Each half is an independent output of Argon2id, so neither key reveals
+// anything about the other.
+//
+// salt should be random and unique per password, and must be at least
+// MinSaltSize bytes long.
+func DeriveKeyPairFromPassword(password, salt []byte) (encKey, authKey nacl.Key, err error) {
+	if len(salt) < MinSaltSize {
+		return nil, nil, errShortSalt
+	}
+	const memory = PasswordHashMemLimit / 1024
+	out := argon2.IDKey(password, salt, PasswordHashOpsLimit, memory, 1, 64)
+	encKey, authKey = new([32]byte), new([32]byte)
+	copy(encKey[:], out[:32])
+	copy(authKey[:], out[32:])
+	for i := range out {
+		out[i] = 0
+	}
+	return encKey, authKey, nil
+}
@@ -0,0 +1,88 @@
+package keyderiv
+
+import (
+	"crypto/rand"
+	"math/bits"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func hammingDistance(a, b nacl.Key) int {
+	d := 0
+	for i := range a {
+		d += bits.OnesCount8(a[i] ^ b[i])
+	}
+	return d
+}
+
+func TestDeriveKeyPairFromPassword(t *testing.T) {
+	salt := make([]byte, MinSaltSize)
+	encKey, authKey, err := DeriveKeyPairFromPassword([]byte("password"), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encKey2, authKey2, err := DeriveKeyPairFromPassword([]byte("password"), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *encKey != *encKey2 || *authKey != *authKey2 {
+		t.Error("same password and salt produced different keys")
+	}
+	if *encKey == *authKey {
+		t.Error("encryption key equals authentication key")
+	}
+	salt[0] = 1
+	encKey3, authKey3, err := DeriveKeyPairFromPassword([]byte("password"), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *encKey3 == *encKey || *authKey3 == *authKey {
+		t.Error("different salts produced the same keys")
+	}
+	if _, _, err := DeriveKeyPairFromPassword([]byte("password"), salt[:MinSaltSize-1]); err != errShortSalt {
+		t.Errorf("got error %v, want %v", err, errShortSalt)
+	}
+}
+
+// For independent keys, each of the 256 bits differs with probability 1/2,
+// so the Hamming distance between two keys averages 128 bits.
+func TestDeriveKeyPairFromPasswordIndependent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow key derivation in short mode")
+	}
+	const n = 6
+	salt := make([]byte, MinSaltSize)
+	var pairDistance, encFlip, authFlip int
+	for i := 0; i < n; i++ {
+		password := make([]byte, 16)
+		rand.Read(password)
+		encKey, authKey, err := DeriveKeyPairFromPassword(password, salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairDistance += hammingDistance(encKey, authKey)
+
+		password[i] ^= 1
+		encKey2, authKey2, err := DeriveKeyPairFromPassword(password, salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encFlip += hammingDistance(encKey, encKey2)
+		authFlip += hammingDistance(authKey, authKey2)
+	}
+	// The standard deviation of the mean of n distances is 8/sqrt(n) bits,
+	// so these bounds are more than five standard deviations wide.
+	for _, tt := range []struct {
+		name string
+		sum  int
+	}{
+		{"encryption and authentication keys", pairDistance},
+		{"encryption keys after a bit flip", encFlip},
+		{"authentication keys after a bit flip", authFlip},
+	} {
+		if mean := float64(tt.sum) / n; mean < 110 || mean > 146 {
+			t.Errorf("%s: mean Hamming distance %.1f, want about 128", tt.name, mean)
+		}
+	}
+}
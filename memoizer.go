@@ -0,0 +1,34 @@
+package nacl
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+)
+
+// A KeyMemoizer derives per-user keys from a master key. It is safe for
+// concurrent use by multiple goroutines.
+//
+// Despite the name, a KeyMemoizer does not cache derived keys. A cache would
+// make lookups for recently seen users faster than lookups for other users,
+// letting an attacker who can time requests learn which users are active.
+// Deriving a key is a single HMAC, so Get computes it every time, in time that
+// does not depend on userID.
+type KeyMemoizer struct {
+	master [32]byte
+}
+
+// NewKeyMemoizer returns a KeyMemoizer that derives keys from master. master
+// is copied, so later changes to it do not affect the KeyMemoizer.
+func NewKeyMemoizer(master Key) *KeyMemoizer {
+	return &KeyMemoizer{master: *master}
+}
+
+// Get returns the key for userID: the first 32 bytes of
+// HMAC-SHA-512(master, userID). This is the same as auth.Sum(userID[:], master).
+func (m *KeyMemoizer) Get(userID [16]byte) Key {
+	mac := hmac.New(sha512.New, m.master[:])
+	mac.Write(userID[:])
+	key := new([32]byte)
+	copy(key[:], mac.Sum(nil))
+	return key
+}
@@ -0,0 +1,54 @@
+package nacl
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestKeyMemoizer(t *testing.T) {
+	master := NewKey()
+	m := NewKeyMemoizer(master)
+	alice := [16]byte{'a', 'l', 'i', 'c', 'e'}
+	bob := [16]byte{'b', 'o', 'b'}
+	a := m.Get(alice)
+	if *a == *master {
+		t.Fatalf("derived key equals master key")
+	}
+	if *m.Get(alice) != *a {
+		t.Errorf("Get is not deterministic")
+	}
+	if *m.Get(bob) == *a {
+		t.Errorf("different users got the same key")
+	}
+	if *NewKeyMemoizer(NewKey()).Get(alice) == *a {
+		t.Errorf("different master keys produced the same key")
+	}
+	master[0] ^= 1
+	if *m.Get(alice) != *a {
+		t.Errorf("modifying the master key changed the derived key")
+	}
+}
+
+// Run with -race to check for concurrent access to shared state.
+func TestKeyMemoizerConcurrent(t *testing.T) {
+	m := NewKeyMemoizer(NewKey())
+	want := make([]Key, 16)
+	for i := range want {
+		want[i] = m.Get([16]byte{byte(i)})
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				i := j % len(want)
+				if got := m.Get([16]byte{byte(i)}); *got != *want[i] {
+					t.Errorf("user %d: got %x, want %x", i, got, want[i])
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
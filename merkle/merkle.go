@@ -0,0 +1,142 @@
+// Package merkle builds Merkle trees over leaves encrypted with secretbox, so
+// that anyone can check that an encrypted leaf is part of a tree, while only
+// holders of the key can read it.
+//
+// Hashes are 32 byte BLAKE2b-256 digests. A leaf's hash is the hash of a zero
+// byte followed by the encrypted leaf, and an internal node's hash is the
+// hash of a one byte followed by the hashes of its two children. The number
+// of leaves is padded to a power of two with all-zero hashes.
+package merkle
+
+import (
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+	"golang.org/x/crypto/blake2b"
+)
+
+var errIndex = errors.New("merkle: index out of range")
+
+// A MerkleTree is an append-only list of encrypted leaves and the Merkle tree
+// over them. A MerkleTree is not safe for concurrent use.
+type MerkleTree struct {
+	key    nacl.Key
+	leaves [][]byte
+	hashes [][32]byte
+}
+
+// NewMerkleTree returns an empty tree whose leaves are encrypted with key.
+func NewMerkleTree(key nacl.Key) *MerkleTree {
+	return &MerkleTree{key: key}
+}
+
+func leafHash(leaf []byte) [32]byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{0})
+	h.Write(leaf)
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+func nodeHash(left, right [32]byte) [32]byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{1})
+	h.Write(left[:])
+	h.Write(right[:])
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// Append encrypts data with secretbox.EasySeal and adds it to the tree as a
+// new leaf. It returns the leaf's index and hash.
+func (t *MerkleTree) Append(data []byte) (index uint64, hash [32]byte) {
+	leaf := secretbox.EasySeal(data, t.key)
+	hash = leafHash(leaf)
+	t.leaves = append(t.leaves, leaf)
+	t.hashes = append(t.hashes, hash)
+	return uint64(len(t.leaves) - 1), hash
+}
+
+// Len returns the number of leaves in the tree.
+func (t *MerkleTree) Len() uint64 {
+	return uint64(len(t.leaves))
+}
+
+// levels returns each level of the tree, starting with the padded leaf
+// hashes and ending with the root.
+func (t *MerkleTree) levels() [][][32]byte {
+	n := 1
+	for n < len(t.hashes) {
+		n *= 2
+	}
+	level := make([][32]byte, n)
+	copy(level, t.hashes)
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, len(level)/2)
+		for i := range next {
+			next[i] = nodeHash(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// Root returns the hash of the root of the tree. The root of an empty tree is
+// all zeros.
+func (t *MerkleTree) Root() [32]byte {
+	levels := t.levels()
+	return levels[len(levels)-1][0]
+}
+
+// EncryptedLeaf returns the encrypted leaf at index, as passed to VerifyProof.
+func (t *MerkleTree) EncryptedLeaf(index uint64) ([]byte, error) {
+	if index >= uint64(len(t.leaves)) {
+		return nil, errIndex
+	}
+	return t.leaves[index], nil
+}
+
+// Prove decrypts and returns the leaf at index, along with a proof that its
+// encrypted form is in the tree with the current root. The proof lists the
+// hashes of the leaf's siblings on the path to the root, starting from the
+// bottom.
+func (t *MerkleTree) Prove(index uint64) (data []byte, proof [][32]byte, err error) {
+	if index >= uint64(len(t.leaves)) {
+		return nil, nil, errIndex
+	}
+	data, err = secretbox.EasyOpen(t.leaves[index], t.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	levels := t.levels()
+	i := index
+	for _, level := range levels[:len(levels)-1] {
+		proof = append(proof, level[i^1])
+		i /= 2
+	}
+	return data, proof, nil
+}
+
+// VerifyProof reports whether proof shows that leaf, an encrypted leaf as
+// returned by EncryptedLeaf, is at index in the tree with the given root. It
+// does not need the key.
+func VerifyProof(index uint64, leaf []byte, proof [][32]byte, root [32]byte) bool {
+	if len(proof) < 64 && index>>uint(len(proof)) != 0 {
+		return false
+	}
+	hash := leafHash(leaf)
+	for _, sibling := range proof {
+		if index&1 == 0 {
+			hash = nodeHash(hash, sibling)
+		} else {
+			hash = nodeHash(sibling, hash)
+		}
+		index >>= 1
+	}
+	return hash == root
+}
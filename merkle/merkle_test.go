@@ -0,0 +1,94 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestProve(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		tree := NewMerkleTree(nacl.NewKey())
+		for i := 0; i < n; i++ {
+			index, _ := tree.Append([]byte(fmt.Sprintf("entry %d", i)))
+			if index != uint64(i) {
+				t.Fatalf("got index %d, want %d", index, i)
+			}
+		}
+		root := tree.Root()
+		for i := 0; i < n; i++ {
+			data, proof, err := tree.Prove(uint64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("entry %d", i); string(data) != want {
+				t.Errorf("%d leaves: leaf %d: got %q, want %q", n, i, data, want)
+			}
+			leaf, err := tree.EncryptedLeaf(uint64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyProof(uint64(i), leaf, proof, root) {
+				t.Errorf("%d leaves: could not verify proof for leaf %d", n, i)
+			}
+			if VerifyProof(uint64(i)^1, leaf, proof, root) && n > 1 {
+				t.Errorf("%d leaves: verified proof for leaf %d at wrong index", n, i)
+			}
+			if VerifyProof(uint64(i)+uint64(1)<<uint(len(proof)), leaf, proof, root) {
+				t.Errorf("%d leaves: verified proof for leaf %d at out of range index", n, i)
+			}
+			if _, err := tree.EncryptedLeaf(uint64(n)); err != errIndex {
+				t.Errorf("expected index error, got %v", err)
+			}
+		}
+	}
+}
+
+func TestTamper(t *testing.T) {
+	tree := NewMerkleTree(nacl.NewKey())
+	for i := 0; i < 4; i++ {
+		tree.Append([]byte{byte(i)})
+	}
+	root := tree.Root()
+	_, proof, err := tree.Prove(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := tree.EncryptedLeaf(2)
+	bad := append([]byte{}, leaf...)
+	bad[len(bad)-1] ^= 0x01
+	if VerifyProof(2, bad, proof, root) {
+		t.Error("verified modified leaf")
+	}
+	proof[1][0] ^= 0x01
+	if VerifyProof(2, leaf, proof, root) {
+		t.Error("verified modified proof")
+	}
+
+	// Appending changes the root, so old proofs don't verify against it.
+	_, proof, _ = tree.Prove(2)
+	tree.Append([]byte("new"))
+	if VerifyProof(2, leaf, proof, tree.Root()) {
+		t.Error("verified proof against new root")
+	}
+	if _, _, err := tree.Prove(5); err != errIndex {
+		t.Errorf("expected index error, got %v", err)
+	}
+}
+
+func TestLeafHash(t *testing.T) {
+	tree := NewMerkleTree(nacl.NewKey())
+	_, hash := tree.Append([]byte("hello"))
+	leaf, _ := tree.EncryptedLeaf(0)
+	if hash != leafHash(leaf) {
+		t.Error("Append returned wrong leaf hash")
+	}
+	// A single leaf is the root.
+	if tree.Root() != hash {
+		t.Error("root of single leaf tree is not the leaf hash")
+	}
+	if NewMerkleTree(nacl.NewKey()).Root() != ([32]byte{}) {
+		t.Error("root of empty tree is not zero")
+	}
+}
@@ -0,0 +1,23 @@
+package nacl
+
+// KeyFromArrayPtr returns p as a Key, for passing keys from
+// golang.org/x/crypto/nacl, which uses *[32]byte, to this package. The Key
+// and p share the same array: no copy is made, so later changes to *p, such
+// as zeroing it, are visible through the Key, and the reverse. To get an
+// independent key, copy the array first:
+//
+//	k := new([32]byte)
+//	*k = *p
+//	key := nacl.KeyFromArrayPtr(k)
+//
+// A nil p gives a nil Key. Since Key is defined as *[32]byte, a Key can be
+// passed back to golang.org/x/crypto/nacl functions as (*[32]byte)(key).
+func KeyFromArrayPtr(p *[32]byte) Key {
+	return Key(p)
+}
+
+// NonceFromArrayPtr returns p as a Nonce. As with KeyFromArrayPtr, the
+// Nonce and p share the same array.
+func NonceFromArrayPtr(p *[24]byte) Nonce {
+	return Nonce(p)
+}
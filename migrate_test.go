@@ -0,0 +1,68 @@
+package nacl_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/box"
+	"github.com/kevinburke/nacl/secretbox"
+	xbox "golang.org/x/crypto/nacl/box"
+	xsecretbox "golang.org/x/crypto/nacl/secretbox"
+)
+
+func TestKeyFromArrayPtrSecretbox(t *testing.T) {
+	var keyArr [32]byte
+	var nonceArr [24]byte
+	rand.Read(keyArr[:])
+	rand.Read(nonceArr[:])
+	message := []byte("migrating from x/crypto")
+
+	want := xsecretbox.Seal(nil, message, &nonceArr, &keyArr)
+	got := secretbox.Seal(nil, message, nacl.NonceFromArrayPtr(&nonceArr), nacl.KeyFromArrayPtr(&keyArr))
+	if !bytes.Equal(got, want) {
+		t.Errorf("secretbox output differs from x/crypto: got %x, want %x", got, want)
+	}
+}
+
+func TestKeyFromArrayPtrBox(t *testing.T) {
+	pub, priv, err := xbox.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerPub, _, err := xbox.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nonceArr [24]byte
+	rand.Read(nonceArr[:])
+	message := []byte("migrating from x/crypto")
+
+	want := xbox.Seal(nil, message, &nonceArr, peerPub, priv)
+	got := box.Seal(nil, message, nacl.NonceFromArrayPtr(&nonceArr), nacl.KeyFromArrayPtr(peerPub), nacl.KeyFromArrayPtr(priv))
+	if !bytes.Equal(got, want) {
+		t.Errorf("box output differs from x/crypto: got %x, want %x", got, want)
+	}
+	if *nacl.KeyFromArrayPtr(pub) != *pub {
+		t.Error("adapted public key differs")
+	}
+}
+
+func TestKeyFromArrayPtrAliases(t *testing.T) {
+	var keyArr [32]byte
+	key := nacl.KeyFromArrayPtr(&keyArr)
+	keyArr[0] = 1
+	if key[0] != 1 {
+		t.Error("Key does not share the array")
+	}
+	var nonceArr [24]byte
+	nonce := nacl.NonceFromArrayPtr(&nonceArr)
+	nonce[0] = 2
+	if nonceArr[0] != 2 {
+		t.Error("Nonce does not share the array")
+	}
+	if nacl.KeyFromArrayPtr(nil) != nil {
+		t.Error("nil pointer gave a non-nil Key")
+	}
+}
@@ -82,6 +82,32 @@ func Verify(a, b []byte) bool {
 	return subtle.ConstantTimeCompare(a, b) == 1
 }
 
+// SliceEqual returns true if and only if a and b have the same length and
+// equal contents. Unlike Verify, which returns as soon as it sees that the
+// lengths differ, SliceEqual always examines max(len(a), len(b)) bytes, so its
+// running time does not reveal whether the lengths matched. It does reveal
+// the length of the longer slice.
+func SliceEqual(a, b []byte) bool {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	var v byte
+	for i := 0; i < n; i++ {
+		var x, y byte
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		v |= x ^ y
+	}
+	d := uint64(len(a) ^ len(b))
+	lengthsEqual := subtle.ConstantTimeEq(int32(uint32(d)|uint32(d>>32)), 0)
+	return lengthsEqual&subtle.ConstantTimeByteEq(v, 0) == 1
+}
+
 // Verify16 returns true if and only if a and b have equal contents.
 func Verify16(a, b *[16]byte) bool {
 	if a == nil || b == nil {
@@ -39,3 +39,25 @@ func TestSecretKey(t *testing.T) {
 		t.Errorf("could not roundtrip decoded key: %s", h)
 	}
 }
+
+var sliceEqualTests = []struct {
+	a, b []byte
+	want bool
+}{
+	{nil, nil, true},
+	{nil, []byte{}, true},
+	{[]byte("abc"), []byte("abc"), true},
+	{[]byte("abc"), []byte("abd"), false},
+	{[]byte("abc"), []byte("ab"), false},
+	{[]byte("ab"), []byte("abc"), false},
+	{[]byte{0}, nil, false},
+	{nil, []byte{0, 0}, false},
+}
+
+func TestSliceEqual(t *testing.T) {
+	for _, tt := range sliceEqualTests {
+		if got := SliceEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("SliceEqual(%q, %q): got %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package nacl
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+)
+
+// NonceChain returns the nonce at position index in a hash chain starting at
+// seed. The nonce at index 0 is seed; each subsequent nonce is the first 24
+// bytes of the SHA-512 hash of the previous nonce. Anyone who knows the seed
+// can regenerate the nonce for any index, which is useful for ordered logs
+// where each message's nonce is derived from the one before it. NonceChain
+// takes time proportional to index.
+//
+// Nonces in the chain are distinct unless the chain enters a cycle. Modeling
+// the truncated hash as a random function on 192-bit values, the probability
+// that the first n nonces contain a repeat is about n²/2¹⁹³, which is
+// negligible for any practical n. Two chains started from different seeds can
+// merge, with the same probability; use a distinct random seed for each key.
+func NonceChain(seed [24]byte, index uint64) Nonce {
+	nonce := new([24]byte)
+	*nonce = seed
+	for i := uint64(0); i < index; i++ {
+		sum := sha512.Sum512(nonce[:])
+		copy(nonce[:], sum[:24])
+	}
+	return nonce
+}
+
+// NonceFromRowVersion returns a nonce made of rowID and version, each encoded
+// as 8 big-endian bytes, followed by 8 zero bytes. It is intended for
+// databases using optimistic concurrency, where each update to a row
+// increments the row's version, so every update is encrypted with a fresh
+// nonce without storing one.
+//
+// The nonce is only unique if the (key, rowID, version) triple is: never
+// encrypt two different values for the same row and version under the same
+// key, for example when retrying a failed update, or when rows in different
+// tables share ids and a key.
+func NonceFromRowVersion(rowID uint64, version uint64) Nonce {
+	nonce := new([24]byte)
+	binary.BigEndian.PutUint64(nonce[:8], rowID)
+	binary.BigEndian.PutUint64(nonce[8:16], version)
+	return nonce
+}
+
+// AssertUniqueNonces returns an error naming the first nonce in nonces that
+// repeats an earlier one, or nil if all of them are distinct. It is meant for
+// use in tests of code that generates nonces, to catch nonce reuse early.
+func AssertUniqueNonces(nonces []Nonce) error {
+	seen := make(map[[24]byte]int, len(nonces))
+	for i, nonce := range nonces {
+		if nonce == nil {
+			return fmt.Errorf("nacl: nonce %d is nil", i)
+		}
+		if j, ok := seen[*nonce]; ok {
+			return fmt.Errorf("nacl: nonce %d (%x) duplicates nonce %d", i, nonce[:], j)
+		}
+		seen[*nonce] = i
+	}
+	return nil
+}
+
+// NonceSequence returns n distinct nonces generated deterministically from
+// seed with math/rand, so that tests can replay the same nonces on every run.
+// The same seed and n always produce the same nonces. NonceSequence panics if
+// n is negative.
+//
+// NonceSequence is for tests only. Anyone who knows or guesses the seed can
+// predict every nonce, and a sequence repeats whenever the seed does, so
+// using it to seal real messages risks nonce reuse. Use NewNonce instead.
+func NonceSequence(seed int64, n int) []Nonce {
+	if n < 0 {
+		panic("nacl: negative NonceSequence length")
+	}
+	r := rand.New(rand.NewSource(seed))
+	nonces := make([]Nonce, 0, n)
+	seen := make(map[[24]byte]bool, n)
+	for len(nonces) < n {
+		nonce := new([24]byte)
+		r.Read(nonce[:])
+		if seen[*nonce] {
+			continue
+		}
+		seen[*nonce] = true
+		nonces = append(nonces, nonce)
+	}
+	return nonces
+}
@@ -0,0 +1,126 @@
+package nacl
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNonceChain(t *testing.T) {
+	var seed [24]byte
+	copy(seed[:], "nonce chain test seed...")
+	if got := NonceChain(seed, 0); *got != seed {
+		t.Errorf("NonceChain(seed, 0): got %x, want seed %x", got, seed)
+	}
+
+	seen := make(map[[24]byte]uint64)
+	prev := seed
+	for i := uint64(1); i <= 1000; i++ {
+		nonce := NonceChain(seed, i)
+		if j, ok := seen[*nonce]; ok {
+			t.Fatalf("nonce at index %d repeats nonce at index %d", i, j)
+		}
+		seen[*nonce] = i
+		sum := sha512.Sum512(prev[:])
+		if string(nonce[:]) != string(sum[:24]) {
+			t.Fatalf("nonce at index %d is not the hash of the previous nonce", i)
+		}
+		if again := NonceChain(seed, i); *again != *nonce {
+			t.Fatalf("NonceChain(seed, %d) is not deterministic", i)
+		}
+		prev = *nonce
+	}
+
+	other := seed
+	other[0] ^= 1
+	if *NonceChain(other, 5) == *NonceChain(seed, 5) {
+		t.Errorf("different seeds produced the same nonce")
+	}
+}
+
+func TestNonceFromRowVersion(t *testing.T) {
+	nonce := NonceFromRowVersion(0x0102030405060708, 0x1112131415161718)
+	want := "010203040506070811121314151617180000000000000000"
+	if got := hex.EncodeToString(nonce[:]); got != want {
+		t.Errorf("got nonce %s, want %s", got, want)
+	}
+
+	seen := make(map[[24]byte]bool)
+	for row := uint64(0); row < 10; row++ {
+		for version := uint64(0); version < 100; version++ {
+			nonce := NonceFromRowVersion(row, version)
+			if seen[*nonce] {
+				t.Fatalf("row %d version %d: duplicate nonce %x", row, version, nonce)
+			}
+			seen[*nonce] = true
+		}
+	}
+	if *NonceFromRowVersion(1, 2) == *NonceFromRowVersion(2, 1) {
+		t.Errorf("row id and version are interchangeable")
+	}
+}
+
+func TestAssertUniqueNonces(t *testing.T) {
+	var nonces []Nonce
+	for i := uint64(0); i < 100; i++ {
+		nonces = append(nonces, NonceFromRowVersion(1, i))
+	}
+	if err := AssertUniqueNonces(nonces); err != nil {
+		t.Fatal(err)
+	}
+	if err := AssertUniqueNonces(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	nonces = append(nonces, NonceFromRowVersion(1, 42))
+	err := AssertUniqueNonces(nonces)
+	if err == nil {
+		t.Fatal("expected error for duplicate nonce")
+	}
+	want := "nacl: nonce 100 (0000000000000001000000000000002a0000000000000000) duplicates nonce 42"
+	if err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+
+	if err := AssertUniqueNonces([]Nonce{NewNonce(), nil}); err == nil || err.Error() != "nacl: nonce 1 is nil" {
+		t.Errorf("expected nil nonce error, got %v", err)
+	}
+}
+
+func TestNonceSequence(t *testing.T) {
+	a := NonceSequence(42, 1000)
+	b := NonceSequence(42, 1000)
+	if len(a) != 1000 {
+		t.Fatalf("got %d nonces, want 1000", len(a))
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			t.Fatalf("nonce %d differs between runs with the same seed", i)
+		}
+	}
+	if err := AssertUniqueNonces(a); err != nil {
+		t.Error(err)
+	}
+	// A shorter sequence is a prefix of a longer one.
+	short := NonceSequence(42, 10)
+	for i := range short {
+		if *short[i] != *a[i] {
+			t.Errorf("nonce %d of a shorter sequence differs", i)
+		}
+	}
+	if c := NonceSequence(43, 1); *c[0] == *a[0] {
+		t.Error("different seeds produced the same nonce")
+	}
+	if len(NonceSequence(1, 0)) != 0 {
+		t.Error("NonceSequence(1, 0) returned nonces")
+	}
+}
+
+func TestNonceSequencePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NonceSequence(1, -1) did not panic")
+		}
+	}()
+	NonceSequence(1, -1)
+}
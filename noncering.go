@@ -0,0 +1,72 @@
+package nacl
+
+import "sync"
+
+// A NonceRing remembers the most recently used nonces, to catch nonce reuse
+// with a key. Reusing a nonce with XSalsa20 and Poly1305 reveals the XOR of
+// the two messages and lets an attacker forge messages, so a NonceRing is a
+// useful last line of defense, but it only remembers a fixed number of nonces:
+// a nonce reused after more than size other nonces goes undetected.
+//
+// A NonceRing is safe for concurrent use by multiple goroutines. Use one
+// NonceRing per key.
+type NonceRing struct {
+	mu   sync.Mutex
+	ring [][24]byte
+	next int
+	full bool
+	seen map[[24]byte]int
+}
+
+// NewNonceRing returns a NonceRing that remembers the last size nonces. It
+// panics if size is not positive.
+func NewNonceRing(size int) *NonceRing {
+	if size <= 0 {
+		panic("nacl: NonceRing size must be positive")
+	}
+	return &NonceRing{
+		ring: make([][24]byte, size),
+		seen: make(map[[24]byte]int, size),
+	}
+}
+
+// record adds nonce to the ring, forgetting the oldest nonce if the ring is
+// full. r.mu must be held.
+func (r *NonceRing) record(nonce [24]byte) {
+	if r.full {
+		old := r.ring[r.next]
+		if r.seen[old] <= 1 {
+			delete(r.seen, old)
+		} else {
+			r.seen[old]--
+		}
+	}
+	r.ring[r.next] = nonce
+	r.seen[nonce]++
+	r.next++
+	if r.next == len(r.ring) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// Use reports whether nonce is safe to use: it returns false if nonce is one
+// of the nonces remembered by the ring. Otherwise, it records nonce and
+// returns true.
+func (r *NonceRing) Use(nonce Nonce) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[*nonce] > 0 {
+		return false
+	}
+	r.record(*nonce)
+	return true
+}
+
+// Mark records nonce as used, whether or not it was already remembered, for
+// example for nonces used before the ring was created.
+func (r *NonceRing) Mark(nonce Nonce) {
+	r.mu.Lock()
+	r.record(*nonce)
+	r.mu.Unlock()
+}
@@ -0,0 +1,88 @@
+package nacl
+
+import (
+	"sync"
+	"testing"
+)
+
+func ringNonce(i byte) Nonce {
+	return &[24]byte{i}
+}
+
+func TestNonceRing(t *testing.T) {
+	r := NewNonceRing(3)
+	for i := byte(0); i < 3; i++ {
+		if !r.Use(ringNonce(i)) {
+			t.Fatalf("nonce %d rejected on first use", i)
+		}
+	}
+	for i := byte(0); i < 3; i++ {
+		if r.Use(ringNonce(i)) {
+			t.Errorf("reused nonce %d accepted", i)
+		}
+	}
+	// Using nonce 3 pushes nonce 0 out of the ring.
+	if !r.Use(ringNonce(3)) {
+		t.Fatal("nonce 3 rejected on first use")
+	}
+	if !r.Use(ringNonce(0)) {
+		t.Error("nonce 0 still remembered after it left the ring")
+	}
+	if r.Use(ringNonce(3)) {
+		t.Error("reused nonce 3 accepted")
+	}
+}
+
+func TestNonceRingMark(t *testing.T) {
+	r := NewNonceRing(3)
+	r.Mark(ringNonce(1))
+	if r.Use(ringNonce(1)) {
+		t.Error("marked nonce accepted")
+	}
+	// Marking a nonce twice keeps it until both entries leave the ring.
+	r.Mark(ringNonce(1))
+	r.Mark(ringNonce(2))
+	r.Mark(ringNonce(3))
+	if r.Use(ringNonce(1)) {
+		t.Error("nonce 1 forgotten while still in the ring")
+	}
+	r.Mark(ringNonce(4))
+	if !r.Use(ringNonce(1)) {
+		t.Error("nonce 1 still remembered after it left the ring")
+	}
+	if len(r.seen) != 3 {
+		t.Errorf("got %d remembered nonces, want 3", len(r.seen))
+	}
+}
+
+func TestNonceRingConcurrent(t *testing.T) {
+	r := NewNonceRing(1000)
+	nonce := NewNonce()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r.Use(nonce) {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted != 1 {
+		t.Errorf("nonce accepted %d times, want 1", accepted)
+	}
+}
+
+func TestNewNonceRingInvalidSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewNonceRing(0) did not panic")
+		}
+	}()
+	NewNonceRing(0)
+}
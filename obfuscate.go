@@ -0,0 +1,62 @@
+package nacl
+
+import (
+	"sync"
+
+	"github.com/kevinburke/nacl/randombytes"
+)
+
+// An ObfuscatedKey holds a key XORed with a random mask, so that the key
+// does not sit in memory as a single contiguous 32 byte value, which some
+// memory scanners look for.
+//
+// This is obfuscation, not cryptographic protection. The mask is stored
+// next to the masked key, so anyone who can read the process's memory and
+// knows the layout can recover the key, and the key is in memory in full
+// while Use runs. It only defeats scanners that search memory for keys
+// without understanding the program.
+//
+// An ObfuscatedKey is safe for concurrent use by multiple goroutines; calls
+// to Use are serialized.
+type ObfuscatedKey struct {
+	mu     sync.Mutex
+	masked [32]byte
+	mask   [32]byte
+}
+
+// NewObfuscatedKey returns an ObfuscatedKey holding k, masked with mask,
+// which should be random, for example from NewKey. The caller should
+// overwrite k once it is no longer needed.
+func NewObfuscatedKey(k Key, mask Key) *ObfuscatedKey {
+	o := &ObfuscatedKey{mask: *mask}
+	for i := range o.masked {
+		o.masked[i] = k[i] ^ mask[i]
+	}
+	return o
+}
+
+// Use unmasks the key, calls fn with it, and overwrites the unmasked copy
+// once fn returns. fn must not keep a reference to the key, or call Use on
+// the same ObfuscatedKey. Changes fn makes to the key are discarded. Each
+// call to Use masks the key again with a new random mask, so the stored bytes
+// change every time the key is used.
+func (o *ObfuscatedKey) Use(fn func(Key)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key := new([32]byte)
+	defer func() {
+		var newMask [32]byte
+		randombytes.MustRead(newMask[:])
+		// Mask again from the stored value rather than from key, which fn
+		// may have modified.
+		for i := range o.masked {
+			o.masked[i] ^= o.mask[i] ^ newMask[i]
+			key[i] = 0
+		}
+		o.mask = newMask
+	}()
+	for i := range key {
+		key[i] = o.masked[i] ^ o.mask[i]
+	}
+	fn(key)
+}
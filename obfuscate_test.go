@@ -0,0 +1,77 @@
+package nacl
+
+import "testing"
+
+func TestObfuscatedKey(t *testing.T) {
+	key := NewKey()
+	want := *key
+	o := NewObfuscatedKey(key, NewKey())
+	if o.masked == want {
+		t.Fatal("key stored unmasked")
+	}
+
+	var seen [][32]byte
+	for i := 0; i < 3; i++ {
+		before := o.masked
+		var got [32]byte
+		o.Use(func(k Key) {
+			got = *k
+		})
+		if got != want {
+			t.Errorf("use %d: got key %x, want %x", i, got, want)
+		}
+		if o.masked == before {
+			t.Errorf("use %d: mask was not changed", i)
+		}
+		seen = append(seen, o.masked)
+	}
+	if seen[0] == seen[1] || seen[1] == seen[2] {
+		t.Error("masked key repeated across uses")
+	}
+
+	// The unmasked copy passed to fn is overwritten when fn returns.
+	var leaked Key
+	o.Use(func(k Key) {
+		leaked = k
+	})
+	if *leaked != [32]byte{} {
+		t.Error("unmasked key was not overwritten after Use")
+	}
+
+	// Writing to the key passed to fn does not change the stored key.
+	o.Use(func(k Key) {
+		k[0] ^= 1
+		k[31] = 0
+	})
+	o.Use(func(k Key) {
+		if *k != want {
+			t.Error("key changed after fn modified it")
+		}
+	})
+
+	// Changing the caller's key does not affect the ObfuscatedKey.
+	key[0] ^= 1
+	o.Use(func(k Key) {
+		if *k != want {
+			t.Error("key changed after caller modified their copy")
+		}
+	})
+}
+
+func TestObfuscatedKeyPanic(t *testing.T) {
+	key := NewKey()
+	want := *key
+	o := NewObfuscatedKey(key, NewKey())
+	func() {
+		defer func() { recover() }()
+		o.Use(func(k Key) {
+			panic("boom")
+		})
+	}()
+	// A panic in fn does not lose the key or leave the lock held.
+	o.Use(func(k Key) {
+		if *k != want {
+			t.Error("key lost after panic in fn")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+// Package onetime seals secrets that can be opened only once.
+//
+// Seal encrypts a message under a fresh random key, and returns the key as an
+// opener token alongside the box. Open decrypts the box and records that the
+// opener has been used, so a second Open with the same opener fails.
+//
+// The record of used openers is kept in memory, in the current process only.
+// It is lost when the process exits, it is not shared with other processes,
+// and anyone who has a copy of the box and the opener can decrypt it with
+// secretbox.EasyOpen, or with this package in another process. Use it to
+// stop accidental reuse within a process, not to enforce single use against
+// someone who holds the opener. The record grows by 32 bytes for every
+// opened box, and is never pruned.
+package onetime
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// OpenerSize is the size, in bytes, of an opener token.
+const OpenerSize = 32
+
+var (
+	mu sync.Mutex
+	// used holds the SHA-256 hash of every opener that has opened a box, so
+	// that the openers themselves do not stay in memory.
+	used = make(map[[sha256.Size]byte]bool)
+)
+
+// Seal encrypts message under a new random key, and returns the box and the
+// key, as the opener token needed to open it.
+func Seal(message []byte) (box []byte, opener []byte, err error) {
+	key := new([32]byte)
+	if _, err := randombytes.Read(key[:]); err != nil {
+		return nil, nil, err
+	}
+	return secretbox.EasySeal(message, key), key[:], nil
+}
+
+// Open decrypts a box produced by Seal with its opener. It returns false if
+// the box cannot be opened with opener, or if opener has already been used
+// to open a box in this process. A failed Open does not use up the opener.
+func Open(box, opener []byte) ([]byte, bool) {
+	if len(opener) != OpenerSize {
+		return nil, false
+	}
+	h := sha256.Sum256(opener)
+	key := new([32]byte)
+	copy(key[:], opener)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if used[h] {
+		return nil, false
+	}
+	message, err := secretbox.EasyOpen(box, key)
+	if err != nil {
+		return nil, false
+	}
+	used[h] = true
+	return message, true
+}
@@ -0,0 +1,73 @@
+package onetime
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestOpenOnce(t *testing.T) {
+	box, opener, err := Seal([]byte("launch code"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opener) != OpenerSize {
+		t.Fatalf("got opener length %d, want %d", len(opener), OpenerSize)
+	}
+	message, ok := Open(box, opener)
+	if !ok || string(message) != "launch code" {
+		t.Fatalf("got %q, %t, want %q", message, ok, "launch code")
+	}
+	if _, ok := Open(box, opener); ok {
+		t.Error("opened the same box twice")
+	}
+}
+
+func TestFailedOpenDoesNotUseOpener(t *testing.T) {
+	box, opener, err := Seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherBox, _, err := Seal([]byte("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Open(otherBox, opener); ok {
+		t.Fatal("opened a box with another box's opener")
+	}
+	if _, ok := Open(box, opener[:OpenerSize-1]); ok {
+		t.Fatal("opened a box with a short opener")
+	}
+	modified := append([]byte{}, box...)
+	modified[len(modified)-1] ^= 1
+	if _, ok := Open(modified, opener); ok {
+		t.Fatal("opened a modified box")
+	}
+	if message, ok := Open(box, opener); !ok || string(message) != "secret" {
+		t.Errorf("got %q, %t after failed opens, want %q", message, ok, "secret")
+	}
+}
+
+func TestConcurrentOpen(t *testing.T) {
+	box, opener, err := Seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	opened := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := Open(box, opener); ok {
+				mu.Lock()
+				opened++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if opened != 1 {
+		t.Errorf("box opened %d times, want 1", opened)
+	}
+}
@@ -0,0 +1,109 @@
+package nacl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// An OracleTest checks whether a decryption function leaks, through its
+// running time or its result, which part of a modified ciphertext was
+// modified, as a function with a padding oracle does. It is a tool for
+// auditing implementations, not part of any protocol.
+//
+// Timing measurements are noisy. A test that passes is evidence, not proof,
+// that the function is not vulnerable; run it on an idle machine, and more
+// than once.
+type OracleTest struct {
+	// Trials is the number of times each modified ciphertext is decrypted.
+	// NewPaddingOracleTest sets it to 31.
+	Trials int
+
+	// Tolerance is the largest allowed difference between the median
+	// decryption times of any two byte positions, as a fraction of the median
+	// time over all positions. NewPaddingOracleTest sets it to 0.5.
+	Tolerance float64
+
+	ciphertext []byte
+	decryptor  func(ciphertext []byte) bool
+}
+
+// NewPaddingOracleTest returns an OracleTest for decryptor, which should
+// report whether a ciphertext decrypts successfully. ciphertext must be a
+// valid ciphertext for decryptor; Run decrypts copies of it with single bits
+// flipped.
+func NewPaddingOracleTest(ciphertext []byte, decryptor func(ciphertext []byte) bool) *OracleTest {
+	return &OracleTest{
+		Trials:     31,
+		Tolerance:  0.5,
+		ciphertext: ciphertext,
+		decryptor:  decryptor,
+	}
+}
+
+// Run flips the low bit of each byte of the ciphertext in turn, and times the
+// decryptor on the result. The positions are visited in round-robin order,
+// Trials times, so that noise is spread evenly across them.
+//
+// The decryptor is reported as vulnerable if it accepts any modified
+// ciphertext, or if the median times for two positions differ by more than
+// Tolerance. evidence is a text report of the median time and number of
+// accepted ciphertexts for each position.
+func (o *OracleTest) Run() (vulnerable bool, evidence []byte) {
+	n := len(o.ciphertext)
+	if n == 0 || o.Trials <= 0 {
+		return false, nil
+	}
+	times := make([][]time.Duration, n)
+	accepted := make([]int, n)
+	modified := make([]byte, n)
+	for trial := 0; trial < o.Trials; trial++ {
+		for i := 0; i < n; i++ {
+			copy(modified, o.ciphertext)
+			modified[i] ^= 1
+			start := time.Now()
+			ok := o.decryptor(modified)
+			times[i] = append(times[i], time.Since(start))
+			if ok {
+				accepted[i]++
+			}
+		}
+	}
+
+	medians := make([]time.Duration, n)
+	var all []time.Duration
+	for i := range times {
+		medians[i] = median(times[i])
+		all = append(all, times[i]...)
+	}
+	overall := median(all)
+	lo, hi := medians[0], medians[0]
+	for _, m := range medians {
+		if m < lo {
+			lo = m
+		}
+		if m > hi {
+			hi = m
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "median %v, spread %v over %d positions and %d trials\n", overall, hi-lo, n, o.Trials)
+	for i, m := range medians {
+		fmt.Fprintf(&buf, "position %d: median %v, accepted %d/%d\n", i, m, accepted[i], o.Trials)
+		if accepted[i] > 0 {
+			vulnerable = true
+		}
+	}
+	if float64(hi-lo) > o.Tolerance*float64(overall) {
+		vulnerable = true
+	}
+	return vulnerable, buf.Bytes()
+}
+
+func median(d []time.Duration) time.Duration {
+	s := append([]time.Duration(nil), d...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s[len(s)/2]
+}
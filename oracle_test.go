@@ -0,0 +1,70 @@
+package nacl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+// work hashes data rounds times, to make a decryption take measurable time.
+func work(data []byte, rounds int) [32]byte {
+	sum := sha256.Sum256(data)
+	for i := 1; i < rounds; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+	return sum
+}
+
+func TestPaddingOracleResistant(t *testing.T) {
+	ciphertext := bytes.Repeat([]byte{7}, 48)
+	want := work(ciphertext, 200)
+	// Authenticates the whole ciphertext before looking at any of it.
+	decryptor := func(c []byte) bool {
+		return work(c, 200) == want
+	}
+	vulnerable, evidence := NewPaddingOracleTest(ciphertext, decryptor).Run()
+	if vulnerable {
+		t.Errorf("constant time decryptor reported as vulnerable:\n%s", evidence)
+	}
+	if !bytes.Contains(evidence, []byte("position 47: median")) {
+		t.Errorf("evidence is missing positions:\n%s", evidence)
+	}
+}
+
+func TestPaddingOracleTiming(t *testing.T) {
+	ciphertext := bytes.Repeat([]byte{7}, 48)
+	// Checks the "padding" in the last 16 bytes before authenticating, and
+	// does much more work when the padding is valid.
+	decryptor := func(c []byte) bool {
+		if bytes.Equal(c[32:], ciphertext[32:]) {
+			work(c, 2000)
+			return false
+		}
+		work(c, 200)
+		return false
+	}
+	vulnerable, evidence := NewPaddingOracleTest(ciphertext, decryptor).Run()
+	if !vulnerable {
+		t.Errorf("decryptor with timing leak not reported as vulnerable:\n%s", evidence)
+	}
+}
+
+func TestPaddingOracleAccepts(t *testing.T) {
+	ciphertext := make([]byte, 16)
+	vulnerable, evidence := NewPaddingOracleTest(ciphertext, func(c []byte) bool {
+		return c[3] == 1
+	}).Run()
+	if !vulnerable {
+		t.Errorf("decryptor accepting modified ciphertext not reported as vulnerable")
+	}
+	if !bytes.Contains(evidence, []byte("position 3: median")) || !bytes.Contains(evidence, []byte("accepted 31/31")) {
+		t.Errorf("evidence does not show accepted ciphertexts:\n%s", evidence)
+	}
+}
+
+func TestPaddingOracleEmpty(t *testing.T) {
+	vulnerable, evidence := NewPaddingOracleTest(nil, func(c []byte) bool { return false }).Run()
+	if vulnerable || evidence != nil {
+		t.Errorf("got %t, %q for empty ciphertext", vulnerable, evidence)
+	}
+}
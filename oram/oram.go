@@ -0,0 +1,218 @@
+// Package oram stores encrypted values in a way that hides which values are
+// accessed, using Path ORAM (Stefanov et al., "Path ORAM: An Extremely Simple
+// Oblivious RAM Protocol", CCS 2013).
+//
+// Values are kept in fixed size blocks, sealed with secretbox, in the buckets
+// of a binary tree; this is the part that would be held by an untrusted
+// server. Each value is assigned to a random leaf and is always somewhere on
+// the path from the root to that leaf, or in a small client-side stash. Every
+// access, whether it reads or writes, reads one whole path, assigns the value
+// a new random leaf, and writes the path back with every block re-encrypted
+// under a fresh nonce. Someone who can see the tree learns only that a random
+// path was accessed.
+//
+// The position map and stash stay on the client, and are not encrypted.
+package oram
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+const (
+	// MaxValueSize is the size, in bytes, of the largest value that can be
+	// stored. Every block is padded to this size.
+	MaxValueSize = 1024
+
+	// BucketSize is the number of blocks in each bucket of the tree.
+	BucketSize = 4
+
+	// blockHeader is a flag byte, set for real blocks and clear for dummy
+	// blocks, the block's id and the length of its value.
+	blockHeader = 1 + 8 + 4
+	blockSize   = blockHeader + MaxValueSize
+)
+
+var (
+	errInvalidInput = errors.New("oram: Could not decrypt invalid input")
+	errFull         = errors.New("oram: capacity exceeded")
+	errTooLarge     = fmt.Errorf("oram: value larger than %d bytes", MaxValueSize)
+)
+
+// An ObliviousBox stores up to a fixed number of values under uint64 ids,
+// hiding which ids are accessed. An ObliviousBox is not safe for concurrent
+// use.
+type ObliviousBox struct {
+	key      nacl.Key
+	capacity int
+	levels   int // number of levels below the root
+	// tree holds the sealed blocks, BucketSize per bucket. Bucket i has
+	// children 2i+1 and 2i+2; the leaves are the last 1<<levels buckets.
+	tree [][]byte
+
+	position map[uint64]uint64 // id to leaf
+	stash    map[uint64][]byte // id to value
+}
+
+// NewObliviousBox returns an empty ObliviousBox that can store up to capacity
+// values, encrypted with key. It panics if capacity is not positive.
+func NewObliviousBox(capacity int, key nacl.Key) *ObliviousBox {
+	if capacity <= 0 {
+		panic("oram: capacity must be positive")
+	}
+	levels := 0
+	for 1<<uint(levels) < capacity {
+		levels++
+	}
+	b := &ObliviousBox{
+		key:      key,
+		capacity: capacity,
+		levels:   levels,
+		tree:     make([][]byte, (1<<uint(levels+1)-1)*BucketSize),
+		position: make(map[uint64]uint64),
+		stash:    make(map[uint64][]byte),
+	}
+	for i := range b.tree {
+		b.tree[i] = b.seal(false, 0, nil)
+	}
+	return b
+}
+
+func (b *ObliviousBox) seal(real bool, id uint64, value []byte) []byte {
+	plaintext := make([]byte, blockSize)
+	if real {
+		plaintext[0] = 1
+	}
+	binary.BigEndian.PutUint64(plaintext[1:], id)
+	binary.BigEndian.PutUint32(plaintext[9:], uint32(len(value)))
+	copy(plaintext[blockHeader:], value)
+	return secretbox.EasySeal(plaintext, b.key)
+}
+
+func (b *ObliviousBox) open(block []byte) (real bool, id uint64, value []byte, err error) {
+	plaintext, err := secretbox.EasyOpen(block, b.key)
+	if err != nil || len(plaintext) != blockSize {
+		return false, 0, nil, errInvalidInput
+	}
+	n := binary.BigEndian.Uint32(plaintext[9:])
+	if n > MaxValueSize {
+		return false, 0, nil, errInvalidInput
+	}
+	id = binary.BigEndian.Uint64(plaintext[1:])
+	return plaintext[0] == 1, id, plaintext[blockHeader : blockHeader+n], nil
+}
+
+func (b *ObliviousBox) randomLeaf() uint64 {
+	var buf [8]byte
+	randombytes.MustRead(buf[:])
+	// The number of leaves is a power of two, so this is uniform.
+	return binary.BigEndian.Uint64(buf[:]) & (1<<uint(b.levels) - 1)
+}
+
+// path returns the buckets on the path from the root to leaf, root first.
+func (b *ObliviousBox) path(leaf uint64) []int {
+	path := make([]int, b.levels+1)
+	node := int(leaf) + 1<<uint(b.levels) - 1
+	for level := b.levels; level >= 0; level-- {
+		path[level] = node
+		node = (node - 1) / 2
+	}
+	return path
+}
+
+// access reads the path for id into the stash, calls update with the stash,
+// remaps id to a new leaf, and writes the path back.
+func (b *ObliviousBox) access(id uint64, update func()) error {
+	leaf, ok := b.position[id]
+	if !ok {
+		leaf = b.randomLeaf()
+	}
+	path := b.path(leaf)
+	// Open the whole path before touching the stash, so a block that fails
+	// to open leaves the stash and the tree as they were.
+	type entry struct {
+		id    uint64
+		value []byte
+	}
+	var read []entry
+	for _, bucket := range path {
+		for _, block := range b.tree[bucket*BucketSize : (bucket+1)*BucketSize] {
+			real, blockID, value, err := b.open(block)
+			if err != nil {
+				return err
+			}
+			if real {
+				read = append(read, entry{blockID, value})
+			}
+		}
+	}
+	for _, e := range read {
+		b.stash[e.id] = e.value
+	}
+	update()
+	if _, ok := b.stash[id]; ok {
+		b.position[id] = b.randomLeaf()
+	}
+
+	// Write back from the leaf up, putting each stashed block as deep as
+	// its own path allows.
+	for level := b.levels; level >= 0; level-- {
+		bucket := path[level]
+		blocks := b.tree[bucket*BucketSize : (bucket+1)*BucketSize]
+		n := 0
+		for blockID, value := range b.stash {
+			if n == BucketSize {
+				break
+			}
+			if b.path(b.position[blockID])[level] != bucket {
+				continue
+			}
+			blocks[n] = b.seal(true, blockID, value)
+			delete(b.stash, blockID)
+			n++
+		}
+		for ; n < BucketSize; n++ {
+			blocks[n] = b.seal(false, 0, nil)
+		}
+	}
+	return nil
+}
+
+// Store stores value under id, replacing any existing value. It returns an
+// error if value is larger than MaxValueSize, or if id is new and the box
+// already holds its capacity of values.
+func (b *ObliviousBox) Store(id uint64, value []byte) error {
+	if len(value) > MaxValueSize {
+		return errTooLarge
+	}
+	if _, ok := b.position[id]; !ok && len(b.position) >= b.capacity {
+		return errFull
+	}
+	v := append([]byte{}, value...)
+	return b.access(id, func() {
+		b.stash[id] = v
+	})
+}
+
+// Retrieve returns the value stored under id. Retrieving an id that has not
+// been stored returns an error, but accesses a random path like any other
+// call, so it is indistinguishable to someone watching the tree.
+func (b *ObliviousBox) Retrieve(id uint64) ([]byte, error) {
+	var value []byte
+	var found bool
+	err := b.access(id, func() {
+		value, found = b.stash[id]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("oram: no value for id %d", id)
+	}
+	return append([]byte{}, value...), nil
+}
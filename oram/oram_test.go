@@ -0,0 +1,132 @@
+package oram
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestStoreRetrieve(t *testing.T) {
+	b := NewObliviousBox(64, nacl.NewKey())
+	for i := uint64(0); i < 64; i++ {
+		if err := b.Store(i*1000, []byte(fmt.Sprintf("record %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for round := 0; round < 3; round++ {
+		for i := uint64(0); i < 64; i++ {
+			value, err := b.Retrieve(i * 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("record %d", i); string(value) != want {
+				t.Fatalf("id %d: got %q, want %q", i*1000, value, want)
+			}
+		}
+	}
+	if err := b.Store(5000, []byte("updated")); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := b.Retrieve(5000); err != nil || string(value) != "updated" {
+		t.Errorf("got %q, %v after update", value, err)
+	}
+	if _, err := b.Retrieve(1); err == nil {
+		t.Error("retrieved id that was never stored")
+	}
+	if len(b.stash) > 3*BucketSize {
+		t.Errorf("stash grew to %d blocks", len(b.stash))
+	}
+}
+
+func TestLimits(t *testing.T) {
+	b := NewObliviousBox(2, nacl.NewKey())
+	if err := b.Store(1, make([]byte, MaxValueSize+1)); err != errTooLarge {
+		t.Errorf("expected too large error, got %v", err)
+	}
+	if err := b.Store(1, make([]byte, MaxValueSize)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Store(2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Store(3, nil); err != errFull {
+		t.Errorf("expected full error, got %v", err)
+	}
+	if err := b.Store(2, []byte("overwrite")); err != nil {
+		t.Errorf("could not overwrite in a full box: %v", err)
+	}
+}
+
+// Every access should rewrite exactly the blocks on one root-to-leaf path,
+// whether it reads or writes and whether or not the id exists.
+func TestAccessPattern(t *testing.T) {
+	b := NewObliviousBox(16, nacl.NewKey())
+	for i := uint64(0); i < 16; i++ {
+		if err := b.Store(i, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	accesses := []func() error{
+		func() error { _, err := b.Retrieve(3); return err },
+		func() error { return b.Store(3, []byte("new")) },
+		func() error { b.Retrieve(99); return nil },
+	}
+	for i, access := range accesses {
+		before := make([][]byte, len(b.tree))
+		copy(before, b.tree)
+		if err := access(); err != nil {
+			t.Fatal(err)
+		}
+		changed := make(map[int]bool)
+		for j := range b.tree {
+			if !bytes.Equal(before[j], b.tree[j]) {
+				changed[j/BucketSize] = true
+			}
+			if len(b.tree[j]) != len(before[j]) {
+				t.Fatalf("access %d: block size changed", i)
+			}
+		}
+		if len(changed) != b.levels+1 {
+			t.Fatalf("access %d: %d buckets changed, want %d", i, len(changed), b.levels+1)
+		}
+		// The changed buckets must form a path: each one's parent changed too.
+		for bucket := range changed {
+			if bucket != 0 && !changed[(bucket-1)/2] {
+				t.Fatalf("access %d: changed buckets do not form a path", i)
+			}
+		}
+	}
+}
+
+func TestTamper(t *testing.T) {
+	b := NewObliviousBox(4, nacl.NewKey())
+	if err := b.Store(1, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	for i := range b.tree {
+		b.tree[i][len(b.tree[i])-1] ^= 0x01
+	}
+	if _, err := b.Retrieve(1); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestTamperLeavesStash(t *testing.T) {
+	b := NewObliviousBox(4, nacl.NewKey())
+	// Put a real block in the root bucket, which is on every path, and
+	// corrupt every leaf bucket below it.
+	b.tree[0] = b.seal(true, 7, []byte("root"))
+	b.position[7] = 0
+	leaves := (1<<uint(b.levels) - 1) * BucketSize
+	for i := leaves; i < len(b.tree); i++ {
+		b.tree[i][len(b.tree[i])-1] ^= 0x01
+	}
+	if _, err := b.Retrieve(1); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+	if len(b.stash) != 0 {
+		t.Errorf("failed access left %d blocks in the stash", len(b.stash))
+	}
+}
@@ -0,0 +1,134 @@
+// Package pin checks a server's public key against a set of pinned keys, for
+// protocols that identify servers by key rather than through a certificate
+// authority.
+//
+// Pinned keys can be added at any time, but a key can only be removed with a
+// removal proof signed by a designated removal authority, so that code able
+// to reach Remove, but not the authority's private key, cannot unpin a key.
+package pin
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/sign"
+)
+
+// removalContext is prepended to the contents of a removal proof, so that a
+// removal proof cannot be mistaken for a signature of any other message.
+const removalContext = "nacl pin removal\x00"
+
+var (
+	errNotPinned    = errors.New("pin: key is not pinned")
+	errInvalidProof = errors.New("pin: invalid removal proof")
+	errStaleProof   = errors.New("pin: removal proof predates the pin")
+)
+
+var now = time.Now
+
+func removalMessage(pub nacl.Key, t time.Time) []byte {
+	msg := make([]byte, 0, len(removalContext)+32+len("remove")+8)
+	msg = append(msg, removalContext...)
+	msg = append(msg, pub[:]...)
+	msg = append(msg, "remove"...)
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(t.Unix()))
+	return append(msg, ts[:]...)
+}
+
+// RemovalProof returns a proof, signed by the removal authority's private
+// key, that pub should be unpinned, for use with CertificatePin.Remove. The
+// proof is a sign.Sign signature of pub, the string "remove", and t, the time
+// of removal, in seconds.
+func RemovalProof(pub nacl.Key, t time.Time, authority sign.PrivateKey) []byte {
+	return sign.Sign(removalMessage(pub, t), authority)
+}
+
+type pinnedKey struct {
+	key   [32]byte
+	added int64 // Unix time, in seconds, that the key was pinned
+}
+
+// A CertificatePin is a set of pinned public keys. It is safe for concurrent
+// use by multiple goroutines.
+type CertificatePin struct {
+	authority sign.PublicKey
+
+	mu   sync.RWMutex
+	pins []pinnedKey
+}
+
+// NewCertificatePin returns a CertificatePin that pins trustedKeys, and
+// accepts removal proofs signed by authority. It panics if authority is not
+// sign.PublicKeySize bytes long.
+func NewCertificatePin(trustedKeys []nacl.Key, authority sign.PublicKey) *CertificatePin {
+	if len(authority) != sign.PublicKeySize {
+		panic("pin: bad removal authority key length")
+	}
+	p := &CertificatePin{authority: authority}
+	for _, key := range trustedKeys {
+		p.Add(key)
+	}
+	return p
+}
+
+// Verify reports whether serverPub is pinned. It compares serverPub to every
+// pinned key, in constant time, so the time it takes depends only on the
+// number of pins and not on which, if any, matched.
+func (p *CertificatePin) Verify(serverPub nacl.Key) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	match := 0
+	for i := range p.pins {
+		match |= subtle.ConstantTimeCompare(p.pins[i].key[:], serverPub[:])
+	}
+	return match == 1
+}
+
+// Add pins pub. Adding a key that is already pinned has no effect.
+func (p *CertificatePin) Add(pub nacl.Key) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.pins {
+		if p.pins[i].key == *pub {
+			return
+		}
+	}
+	p.pins = append(p.pins, pinnedKey{key: *pub, added: now().Unix()})
+}
+
+// Remove unpins pub, if removalProof is a proof from RemovalProof, signed by
+// the removal authority, that pub should be removed. The time in the proof
+// must not be earlier than the time pub was pinned, so a proof cannot be
+// replayed to remove a key that was pinned again after an earlier removal.
+func (p *CertificatePin) Remove(pub nacl.Key, removalProof []byte) error {
+	if !sign.Verify(removalProof, p.authority) {
+		return errInvalidProof
+	}
+	msg := removalProof[sign.SignatureSize:]
+	if len(msg) != len(removalContext)+32+len("remove")+8 ||
+		string(msg[:len(removalContext)]) != removalContext ||
+		subtle.ConstantTimeCompare(msg[len(removalContext):len(removalContext)+32], pub[:]) != 1 ||
+		string(msg[len(removalContext)+32:len(msg)-8]) != "remove" {
+		return errInvalidProof
+	}
+	ts := int64(binary.BigEndian.Uint64(msg[len(msg)-8:]))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.pins {
+		if p.pins[i].key != *pub {
+			continue
+		}
+		if ts < p.pins[i].added {
+			return errStaleProof
+		}
+		p.pins = append(p.pins[:i], p.pins[i+1:]...)
+		return nil
+	}
+	return errNotPinned
+}
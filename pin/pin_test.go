@@ -0,0 +1,99 @@
+package pin
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/sign"
+)
+
+func setNow(t time.Time) func() {
+	old := now
+	now = func() time.Time { return t }
+	return func() { now = old }
+}
+
+func TestVerify(t *testing.T) {
+	authPub, _, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := nacl.NewKey(), nacl.NewKey(), nacl.NewKey()
+	p := NewCertificatePin([]nacl.Key{a, b}, authPub)
+	if !p.Verify(a) || !p.Verify(b) {
+		t.Error("pinned key did not verify")
+	}
+	if p.Verify(c) {
+		t.Error("unpinned key verified")
+	}
+	p.Add(c)
+	p.Add(c)
+	if !p.Verify(c) {
+		t.Error("added key did not verify")
+	}
+	if len(p.pins) != 3 {
+		t.Errorf("got %d pins after adding a key twice, want 3", len(p.pins))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	authPub, authPriv, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := sign.Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := time.Unix(1500000000, 0)
+	defer setNow(pinned)()
+	a, b := nacl.NewKey(), nacl.NewKey()
+	p := NewCertificatePin([]nacl.Key{a, b}, authPub)
+
+	later := pinned.Add(time.Hour)
+	proof := RemovalProof(a, later, authPriv)
+	tampered := append([]byte{}, proof...)
+	tampered[len(tampered)-1] ^= 1
+	tests := []struct {
+		name  string
+		proof []byte
+		want  error
+	}{
+		{"wrong authority", RemovalProof(a, later, otherPriv), errInvalidProof},
+		{"proof for another key", RemovalProof(b, later, authPriv), errInvalidProof},
+		{"tampered", tampered, errInvalidProof},
+		{"empty", nil, errInvalidProof},
+		{"plain signature", sign.Sign(a[:], authPriv), errInvalidProof},
+		{"before pin", RemovalProof(a, pinned.Add(-time.Second), authPriv), errStaleProof},
+	}
+	for _, tt := range tests {
+		if err := p.Remove(a, tt.proof); err != tt.want {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.want)
+		}
+		if !p.Verify(a) {
+			t.Fatalf("%s: key removed", tt.name)
+		}
+	}
+
+	if err := p.Remove(a, proof); err != nil {
+		t.Fatal(err)
+	}
+	if p.Verify(a) {
+		t.Error("removed key still verifies")
+	}
+	if !p.Verify(b) {
+		t.Error("removing one key removed another")
+	}
+	if err := p.Remove(a, proof); err != errNotPinned {
+		t.Errorf("removing twice: got error %v, want %v", err, errNotPinned)
+	}
+
+	// An old proof cannot remove the key once it is pinned again.
+	defer setNow(later.Add(time.Hour))()
+	p.Add(a)
+	if err := p.Remove(a, proof); err != errStaleProof {
+		t.Errorf("replayed proof: got error %v, want %v", err, errStaleProof)
+	}
+}
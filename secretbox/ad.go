@@ -0,0 +1,90 @@
+package secretbox
+
+import (
+	"crypto/sha512"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/onetimeauth"
+)
+
+// adKeyLabel separates the key for boxes with associated data from the key
+// passed in, which may also be used with Seal.
+const adKeyLabel = "nacl secretbox associated data"
+
+// adKey derives the key that seals boxes with associated data from key. A
+// separate key is needed because Seal's tag is Poly1305 over the bare
+// ciphertext: under the same one-time key, a box with associated data
+// followed by the associated data's hash would be a valid Seal box.
+func adKey(key nacl.Key) nacl.Key {
+	return auth.Sum([]byte(adKeyLabel), key)
+}
+
+// hashAD reads associated data from r until EOF and returns its SHA-512 hash.
+func hashAD(r io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// SealWithADReader encrypts and authenticates message, and authenticates (but
+// does not encrypt) the associated data read from adReader until EOF. The
+// associated data is never held in memory in full, so it may be arbitrarily
+// large; message is sealed in memory, as with Seal.
+//
+// The output has the same size as the output of Seal. It is sealed with a key
+// derived from key, and its Poly1305 tag covers the ciphertext followed by
+// the SHA-512 hash of the associated data, so it can only be opened with
+// OpenWithADReader and the same associated data, never with Open. The key and
+// nonce pair must be unique for each distinct message.
+func SealWithADReader(message []byte, adReader io.Reader, nonce nacl.Nonce, key nacl.Key) ([]byte, error) {
+	adHash, err := hashAD(adReader)
+	if err != nil {
+		return nil, err
+	}
+
+	var subKey, poly1305Key [32]byte
+	var counter [16]byte
+	var firstBlock [64]byte
+	setupKeyStream(&subKey, &poly1305Key, &counter, &firstBlock, nonce, adKey(key))
+
+	box := make([]byte, Overhead+len(message), Overhead+len(message)+len(adHash))
+	xorKeyStream(box[Overhead:], message, &firstBlock, &counter, &subKey)
+	tag := onetimeauth.Sum(append(box[Overhead:], adHash...), &poly1305Key)
+	copy(box, tag[:])
+	return box, nil
+}
+
+// OpenWithADReader authenticates and decrypts a box produced by
+// SealWithADReader, reading the associated data from adReader until EOF. It
+// returns errInvalidInput if the box or the associated data has been modified.
+func OpenWithADReader(box []byte, adReader io.Reader, nonce nacl.Nonce, key nacl.Key) ([]byte, error) {
+	if len(box) < Overhead {
+		return nil, errInvalidInput
+	}
+	adHash, err := hashAD(adReader)
+	if err != nil {
+		return nil, err
+	}
+
+	var subKey, poly1305Key [32]byte
+	var counter [16]byte
+	var firstBlock [64]byte
+	setupKeyStream(&subKey, &poly1305Key, &counter, &firstBlock, nonce, adKey(key))
+
+	var tag [onetimeauth.Size]byte
+	copy(tag[:], box)
+	authenticated := make([]byte, 0, len(box)-Overhead+len(adHash))
+	authenticated = append(authenticated, box[Overhead:]...)
+	authenticated = append(authenticated, adHash...)
+	if !onetimeauth.Verify(&tag, authenticated, &poly1305Key) {
+		return nil, errInvalidInput
+	}
+
+	message := make([]byte, len(box)-Overhead)
+	xorKeyStream(message, box[Overhead:], &firstBlock, &counter, &subKey)
+	return message, nil
+}
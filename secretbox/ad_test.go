@@ -0,0 +1,109 @@
+package secretbox
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+// patternReader returns n bytes of a repeating, position-dependent pattern,
+// optionally flipping one bit at position flip.
+type patternReader struct {
+	pos, n, flip int
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.pos >= r.n {
+		return 0, io.EOF
+	}
+	if len(p) > r.n-r.pos {
+		p = p[:r.n-r.pos]
+	}
+	for i := range p {
+		p[i] = byte(r.pos*7 + r.pos>>8)
+		if r.pos == r.flip {
+			p[i] ^= 0x01
+		}
+		r.pos++
+	}
+	return len(p), nil
+}
+
+func adReader(n int) io.Reader {
+	return &patternReader{n: n, flip: -1}
+}
+
+func TestSealWithADReader(t *testing.T) {
+	const adSize = 8 << 20
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	for _, size := range []int{0, 1, 32, 33, 1000} {
+		message := bytes.Repeat([]byte{'a'}, size)
+		box, err := SealWithADReader(message, adReader(adSize), nonce, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(box) != len(message)+Overhead {
+			t.Errorf("got box length %d, want %d", len(box), len(message)+Overhead)
+		}
+		opened, err := OpenWithADReader(box, adReader(adSize), nonce, key)
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		if !bytes.Equal(opened, message) {
+			t.Errorf("%d: got %q, want %q", size, opened, message)
+		}
+		// Appending the hash of the associated data does not turn the box
+		// into one that Open accepts.
+		adHash, err := hashAD(adReader(adSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := Open(nil, append(box, adHash...), nonce, key); ok {
+			t.Errorf("%d: Open opened a box with its associated data hash appended", size)
+		}
+	}
+}
+
+func TestOpenWithADReaderModified(t *testing.T) {
+	const adSize = 3 << 20
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	message := []byte("hello world")
+	box, err := SealWithADReader(message, adReader(adSize), nonce, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := &patternReader{n: adSize, flip: adSize / 2}
+	if _, err := OpenWithADReader(box, flipped, nonce, key); err != errInvalidInput {
+		t.Errorf("modified AD: expected invalid input error, got %v", err)
+	}
+	if _, err := OpenWithADReader(box, adReader(adSize-1), nonce, key); err != errInvalidInput {
+		t.Errorf("truncated AD: expected invalid input error, got %v", err)
+	}
+	box[Overhead] ^= 0x01
+	if _, err := OpenWithADReader(box, adReader(adSize), nonce, key); err != errInvalidInput {
+		t.Errorf("modified box: expected invalid input error, got %v", err)
+	}
+	if _, err := OpenWithADReader(box[:Overhead-1], adReader(adSize), nonce, key); err != errInvalidInput {
+		t.Errorf("short box: expected invalid input error, got %v", err)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func TestSealWithADReaderError(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	if _, err := SealWithADReader([]byte("hello"), errReader{}, nonce, key); err == nil || err.Error() != "read error" {
+		t.Errorf("expected read error, got %v", err)
+	}
+}
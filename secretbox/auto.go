@@ -0,0 +1,111 @@
+package secretbox
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+)
+
+// AutoThreshold is the largest message that SealAuto seals as a single box.
+// Larger messages are sealed in chunks of AutoThreshold bytes.
+const AutoThreshold = 64 * 1024
+
+// Format bytes written by SealAuto.
+const (
+	autoBox     = 0
+	autoChunked = 1
+)
+
+// autoSaltSize is the size of the random salt at the start of a chunked box.
+const autoSaltSize = 16
+
+// autoChunkNonce returns the nonce for chunk counter of a chunked box: the
+// salt, the counter as a 7 byte big-endian integer, and a byte that is 1 for
+// the last chunk and 0 otherwise, so a chunked box that has been truncated,
+// reordered or extended fails to open.
+func autoChunkNonce(salt []byte, counter uint64, final bool) nacl.Nonce {
+	nonce := new([24]byte)
+	copy(nonce[:], salt)
+	for i := 22; i >= autoSaltSize; i-- {
+		nonce[i] = byte(counter)
+		counter >>= 8
+	}
+	if final {
+		nonce[23] = 1
+	}
+	return nonce
+}
+
+// SealAuto encrypts message using key, choosing a format by its size. The
+// output starts with a byte indicating the format. Messages of up to
+// AutoThreshold bytes are sealed with EasySeal. Larger messages are split
+// into chunks of AutoThreshold bytes, and each chunk is sealed with Seal
+// under a nonce made of a random salt and the chunk's position, so no single
+// box is larger than AutoThreshold+Overhead bytes. Both formats use
+// XSalsa20 and Poly1305. Use OpenAuto to decrypt the output.
+func SealAuto(message []byte, key nacl.Key) ([]byte, error) {
+	if len(message) <= AutoThreshold {
+		nonce := nacl.NewNonce()
+		out := make([]byte, 1+24, 1+24+len(message)+Overhead)
+		out[0] = autoBox
+		copy(out[1:], nonce[:])
+		return Seal(out, message, nonce, key), nil
+	}
+	chunks := (len(message) + AutoThreshold - 1) / AutoThreshold
+	out := make([]byte, 1+autoSaltSize, 1+autoSaltSize+len(message)+chunks*Overhead)
+	out[0] = autoChunked
+	salt := out[1:]
+	if _, err := randombytes.Read(salt); err != nil {
+		return nil, err
+	}
+	for i := uint64(0); ; i++ {
+		n := len(message)
+		if n > AutoThreshold {
+			n = AutoThreshold
+		}
+		final := n == len(message)
+		out = Seal(out, message[:n], autoChunkNonce(salt, i, final), key)
+		message = message[n:]
+		if final {
+			return out, nil
+		}
+	}
+}
+
+// OpenAuto decrypts the output of SealAuto with key. A chunked box is opened
+// one chunk at a time, and fails if any chunk fails to open.
+func OpenAuto(box []byte, key nacl.Key) ([]byte, error) {
+	if len(box) < 1 {
+		return nil, errInvalidInput
+	}
+	switch box[0] {
+	case autoBox:
+		return EasyOpen(box[1:], key)
+	case autoChunked:
+		if len(box) < 1+autoSaltSize {
+			return nil, errInvalidInput
+		}
+		salt, rest := box[1:1+autoSaltSize], box[1+autoSaltSize:]
+		var message []byte
+		for i := uint64(0); ; i++ {
+			if len(rest) == 0 {
+				return nil, errInvalidInput
+			}
+			n := len(rest)
+			if n > AutoThreshold+Overhead {
+				n = AutoThreshold + Overhead
+			}
+			final := n == len(rest)
+			var ok bool
+			message, ok = Open(message, rest[:n], autoChunkNonce(salt, i, final), key)
+			if !ok {
+				return nil, errInvalidInput
+			}
+			rest = rest[n:]
+			if final {
+				return message, nil
+			}
+		}
+	default:
+		return nil, errInvalidInput
+	}
+}
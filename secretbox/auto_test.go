@@ -0,0 +1,91 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+)
+
+func TestSealAuto(t *testing.T) {
+	key := nacl.NewKey()
+	for _, size := range []int{0, 1, AutoThreshold - 1, AutoThreshold, AutoThreshold + 1, 3 * AutoThreshold} {
+		message := make([]byte, size)
+		randombytes.MustRead(message)
+		box, err := SealAuto(message, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantFormat := byte(autoBox)
+		if size > AutoThreshold {
+			wantFormat = autoChunked
+		}
+		if box[0] != wantFormat {
+			t.Errorf("%d bytes: got format %d, want %d", size, box[0], wantFormat)
+		}
+		opened, err := OpenAuto(box, key)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", size, err)
+		}
+		if !bytes.Equal(opened, message) {
+			t.Errorf("%d bytes: opened message does not match", size)
+		}
+
+		box[len(box)-1] ^= 0x01
+		if _, err := OpenAuto(box, key); err == nil {
+			t.Errorf("%d bytes: opened modified box", size)
+		}
+	}
+}
+
+func TestOpenAutoInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	if _, err := OpenAuto(nil, key); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+	box, err := SealAuto([]byte("hello"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box[0] = 2
+	if _, err := OpenAuto(box, key); err != errInvalidInput {
+		t.Errorf("expected invalid input error for unknown format, got %v", err)
+	}
+	// Claiming the chunked format for a single box must fail.
+	box[0] = autoChunked
+	if _, err := OpenAuto(box, key); err == nil {
+		t.Error("opened box with wrong format byte")
+	}
+}
+
+func TestOpenAutoChunkedTampering(t *testing.T) {
+	key := nacl.NewKey()
+	message := make([]byte, 3*AutoThreshold)
+	randombytes.MustRead(message)
+	box, err := SealAuto(message, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := AutoThreshold + Overhead
+	header := 1 + autoSaltSize
+	swapped := append([]byte{}, box[:header]...)
+	swapped = append(swapped, box[header+chunk:header+2*chunk]...)
+	swapped = append(swapped, box[header:header+chunk]...)
+	swapped = append(swapped, box[header+2*chunk:]...)
+	tests := []struct {
+		name string
+		box  []byte
+	}{
+		{"truncated at a chunk boundary", box[:header+2*chunk]},
+		{"extended", append(append([]byte{}, box...), box[header:header+chunk]...)},
+		{"reordered", swapped},
+		{"salt only", box[:header]},
+		{"short salt", box[:header-1]},
+	}
+	for _, tt := range tests {
+		if _, err := OpenAuto(tt.box, key); err != errInvalidInput {
+			t.Errorf("%s: expected invalid input error, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package secretbox
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+// ErrBudgetExhausted is returned by BudgetedSealer.Seal when sealing the
+// message would exceed the byte budget.
+var ErrBudgetExhausted = errors.New("secretbox: encryption budget exhausted")
+
+var errOverBudget = errors.New("secretbox: message is larger than the budget for one second")
+
+// A BudgetedSealer limits the number of bytes sealed per second, for example
+// to stop one client of a network service from using all of its encryption
+// capacity. It is a token bucket holding up to one second's budget of bytes:
+// each call to Seal takes len(message) bytes from the bucket, and the bucket
+// refills continuously at the budgeted rate.
+//
+// A BudgetedSealer is safe for concurrent use by multiple goroutines.
+type BudgetedSealer struct {
+	key  nacl.Key
+	rate float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewBudgetedSealer returns a BudgetedSealer that seals with key, at most
+// budgetPerSecond bytes per second. The bucket starts full. It panics if
+// budgetPerSecond is not positive.
+func NewBudgetedSealer(key nacl.Key, budgetPerSecond float64) *BudgetedSealer {
+	if !(budgetPerSecond > 0) {
+		panic("secretbox: budget must be positive")
+	}
+	return &BudgetedSealer{
+		key:    key,
+		rate:   budgetPerSecond,
+		tokens: budgetPerSecond,
+		last:   now(),
+	}
+}
+
+// Seal seals message with Seal, nonce and the BudgetedSealer's key, and
+// returns the box. If fewer than len(message) bytes are left in the budget,
+// nothing is sealed and Seal returns ErrBudgetExhausted; the caller may retry
+// later. Seal returns a different error if message is larger than one
+// second's budget, since it could never be sealed.
+func (b *BudgetedSealer) Seal(message []byte, nonce nacl.Nonce) ([]byte, error) {
+	cost := float64(len(message))
+	if cost > b.rate {
+		return nil, errOverBudget
+	}
+	b.mu.Lock()
+	t := now()
+	if elapsed := t.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+	}
+	b.last = t
+	if b.tokens < cost {
+		b.mu.Unlock()
+		return nil, ErrBudgetExhausted
+	}
+	b.tokens -= cost
+	b.mu.Unlock()
+	return Seal(nil, message, nonce, b.key), nil
+}
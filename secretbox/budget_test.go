@@ -0,0 +1,84 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestBudgetedSealer(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer setNow(start)()
+	key := nacl.NewKey()
+	b := NewBudgetedSealer(key, 1000)
+	message := make([]byte, 400)
+
+	for i := 0; i < 2; i++ {
+		nonce := nacl.NewNonce()
+		box, err := b.Seal(message, nonce)
+		if err != nil {
+			t.Fatalf("seal %d: %v", i, err)
+		}
+		opened, ok := Open(nil, box, nonce, key)
+		if !ok || !bytes.Equal(opened, message) {
+			t.Fatalf("seal %d: could not open box", i)
+		}
+	}
+	// 200 bytes are left.
+	if _, err := b.Seal(message, nacl.NewNonce()); err != ErrBudgetExhausted {
+		t.Fatalf("got error %v, want %v", err, ErrBudgetExhausted)
+	}
+	if _, err := b.Seal(message[:200], nacl.NewNonce()); err != nil {
+		t.Fatalf("could not seal remaining budget: %v", err)
+	}
+	if _, err := b.Seal([]byte{1}, nacl.NewNonce()); err != ErrBudgetExhausted {
+		t.Fatalf("got error %v, want %v", err, ErrBudgetExhausted)
+	}
+	// Empty messages cost nothing.
+	if _, err := b.Seal(nil, nacl.NewNonce()); err != nil {
+		t.Errorf("could not seal empty message: %v", err)
+	}
+
+	// The budget refills at 1000 bytes per second.
+	setNow(start.Add(300 * time.Millisecond))
+	if _, err := b.Seal(message, nacl.NewNonce()); err != ErrBudgetExhausted {
+		t.Errorf("got error %v after 300ms, want %v", err, ErrBudgetExhausted)
+	}
+	setNow(start.Add(400 * time.Millisecond))
+	if _, err := b.Seal(message, nacl.NewNonce()); err != nil {
+		t.Errorf("could not seal after 400ms: %v", err)
+	}
+
+	// The bucket holds at most one second's budget.
+	setNow(start.Add(time.Hour))
+	for i := 0; i < 2; i++ {
+		if _, err := b.Seal(make([]byte, 500), nacl.NewNonce()); err != nil {
+			t.Fatalf("seal %d after an hour: %v", i, err)
+		}
+	}
+	if _, err := b.Seal([]byte{1}, nacl.NewNonce()); err != ErrBudgetExhausted {
+		t.Errorf("got error %v, want %v", err, ErrBudgetExhausted)
+	}
+}
+
+func TestBudgetedSealerTooLarge(t *testing.T) {
+	b := NewBudgetedSealer(nacl.NewKey(), 100)
+	if _, err := b.Seal(make([]byte, 101), nacl.NewNonce()); err != errOverBudget {
+		t.Errorf("got error %v, want %v", err, errOverBudget)
+	}
+}
+
+func TestBudgetedSealerInvalidBudget(t *testing.T) {
+	for _, budget := range []float64{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewBudgetedSealer(%v) did not panic", budget)
+				}
+			}()
+			NewBudgetedSealer(nacl.NewKey(), budget)
+		}()
+	}
+}
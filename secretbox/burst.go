@@ -0,0 +1,104 @@
+package secretbox
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/kevinburke/nacl"
+)
+
+var errBurstTooLarge = errors.New("secretbox: message too large for burst buffer")
+
+// A BurstSealer combines bursts of small messages into a single box, saving
+// the cost of setting up XSalsa20 and Poly1305 for each message. Each message
+// is stored in the plaintext with a two byte big-endian length prefix.
+//
+// Each box is sealed with the next nonce in a sequence that starts with the
+// nonce passed to NewBurstSealer and is incremented, as a big-endian integer,
+// after each box. The nonce is prepended to the box, as with EasySeal, so
+// boxes can be opened with OpenBurst in any order.
+//
+// A BurstSealer is not safe for concurrent use.
+type BurstSealer struct {
+	key   nacl.Key
+	nonce [24]byte
+	buf   []byte
+}
+
+// NewBurstSealer returns a BurstSealer that seals messages with key, starting
+// with nonce, and that seals a box whenever the next message would take the
+// plaintext over bufSize bytes. Every nonce in the sequence must be unused
+// with key.
+func NewBurstSealer(key nacl.Key, nonce nacl.Nonce, bufSize int) *BurstSealer {
+	return &BurstSealer{
+		key:   key,
+		nonce: *nonce,
+		buf:   make([]byte, 0, bufSize),
+	}
+}
+
+// Add adds message to the buffer. If there is not enough room for message, the
+// buffered messages are first sealed and the box is returned; otherwise Add
+// returns nil. Add returns an error if message, with its length prefix, would
+// not fit in an empty buffer.
+func (b *BurstSealer) Add(message []byte) ([]byte, error) {
+	if len(message) > 0xffff || 2+len(message) > cap(b.buf) {
+		return nil, errBurstTooLarge
+	}
+	var box []byte
+	if len(b.buf)+2+len(message) > cap(b.buf) {
+		box = b.Flush()
+	}
+	b.buf = append(b.buf, byte(len(message)>>8), byte(len(message)))
+	b.buf = append(b.buf, message...)
+	return box, nil
+}
+
+// Flush seals the buffered messages, and returns the box with its nonce
+// prepended. If there are no buffered messages, Flush returns nil.
+func (b *BurstSealer) Flush() []byte {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	nonce := b.nonce
+	out := make([]byte, 24, 24+len(b.buf)+Overhead)
+	copy(out, nonce[:])
+	out = Seal(out, b.buf, &nonce, b.key)
+	for i := len(b.nonce) - 1; i >= 0; i-- {
+		b.nonce[i]++
+		if b.nonce[i] != 0 {
+			break
+		}
+	}
+	b.buf = b.buf[:0]
+	return out
+}
+
+// OpenBurst authenticates and decrypts a box produced by a BurstSealer, and
+// returns the messages in the order they were added. It returns false if the
+// box cannot be opened or is malformed.
+func OpenBurst(box []byte, key nacl.Key) ([][]byte, bool) {
+	if len(box) < 24 {
+		return nil, false
+	}
+	nonce := new([24]byte)
+	copy(nonce[:], box)
+	plaintext, ok := Open(nil, box[24:], nonce, key)
+	if !ok {
+		return nil, false
+	}
+	var messages [][]byte
+	for len(plaintext) > 0 {
+		if len(plaintext) < 2 {
+			return nil, false
+		}
+		n := int(binary.BigEndian.Uint16(plaintext))
+		plaintext = plaintext[2:]
+		if len(plaintext) < n {
+			return nil, false
+		}
+		messages = append(messages, plaintext[:n:n])
+		plaintext = plaintext[n:]
+	}
+	return messages, true
+}
@@ -0,0 +1,100 @@
+package secretbox
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestBurstSealer(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := new([24]byte)
+	nonce[23] = 0xff
+	b := NewBurstSealer(key, nonce, 64)
+
+	var messages [][]byte
+	var boxes [][]byte
+	for i := 0; i < 20; i++ {
+		message := []byte(fmt.Sprintf("event %d", i))
+		messages = append(messages, message)
+		box, err := b.Add(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if box != nil {
+			boxes = append(boxes, box)
+		}
+	}
+	if box := b.Flush(); box != nil {
+		boxes = append(boxes, box)
+	}
+	if box := b.Flush(); box != nil {
+		t.Error("Flush with empty buffer returned a box")
+	}
+	if len(boxes) < 2 {
+		t.Fatalf("got %d boxes, want several", len(boxes))
+	}
+
+	var opened [][]byte
+	seen := make(map[string]bool)
+	for i, box := range boxes {
+		if len(box) > 24+64+Overhead {
+			t.Errorf("box %d: length %d exceeds buffer size", i, len(box))
+		}
+		if seen[string(box[:24])] {
+			t.Errorf("box %d: repeated nonce", i)
+		}
+		seen[string(box[:24])] = true
+		msgs, ok := OpenBurst(box, key)
+		if !ok {
+			t.Fatalf("could not open box %d", i)
+		}
+		opened = append(opened, msgs...)
+	}
+	// The nonce should carry into the next byte.
+	if boxes[1][22] != 1 || boxes[1][23] != 0 {
+		t.Errorf("got second nonce %x", boxes[1][:24])
+	}
+	if len(opened) != len(messages) {
+		t.Fatalf("got %d messages, want %d", len(opened), len(messages))
+	}
+	for i := range messages {
+		if !bytes.Equal(opened[i], messages[i]) {
+			t.Errorf("message %d: got %q, want %q", i, opened[i], messages[i])
+		}
+	}
+}
+
+func TestBurstSealerErrors(t *testing.T) {
+	key := nacl.NewKey()
+	b := NewBurstSealer(key, nacl.NewNonce(), 10)
+	if _, err := b.Add(make([]byte, 9)); err != errBurstTooLarge {
+		t.Errorf("expected too large error, got %v", err)
+	}
+	if _, err := b.Add(make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+	box, err := b.Add(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box == nil {
+		t.Fatal("expected a full buffer to be sealed")
+	}
+	box[len(box)-1] ^= 0x01
+	if _, ok := OpenBurst(box, key); ok {
+		t.Error("opened modified box")
+	}
+	if _, ok := OpenBurst(box[:10], key); ok {
+		t.Error("opened truncated box")
+	}
+
+	// A box with a length prefix that runs past the end of the plaintext.
+	nonce := nacl.NewNonce()
+	bad := Seal(append([]byte{}, nonce[:]...), []byte{0x00, 0x05, 'a'}, nonce, key)
+	if _, ok := OpenBurst(bad, key); ok {
+		t.Error("opened box with bad length prefix")
+	}
+}
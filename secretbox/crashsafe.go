@@ -0,0 +1,76 @@
+package secretbox
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+)
+
+var errCounterExhausted = errors.New("secretbox: nonce counter exhausted")
+
+// bootSalts holds the salt SealCrashSafe generated for each counter in this
+// process. Holding the counter pointers keeps them from being freed and their
+// addresses reused by another counter.
+var (
+	bootMu    sync.Mutex
+	bootSalts = make(map[*uint64][16]byte)
+)
+
+// bootSalt returns the salt for counter, generating it on first use.
+func bootSalt(counter *uint64) [16]byte {
+	bootMu.Lock()
+	defer bootMu.Unlock()
+	salt, ok := bootSalts[counter]
+	if !ok {
+		randombytes.MustRead(salt[:])
+		bootSalts[counter] = salt
+	}
+	return salt
+}
+
+// SealCrashSafe increments *persistedCounter and seals message with key and a
+// nonce made of a random 16 byte salt followed by the new counter value as an
+// 8 byte big-endian integer. It returns the box, without the nonce, and the
+// nonce, which must be stored with the box to open it with Open.
+//
+// A counter on its own is not enough to avoid reusing nonces in a system that
+// can crash: if boxes are sealed and sent after the counter was last written
+// to disk, the same counter values will be used again after a restart. The
+// salt is chosen at random the first time SealCrashSafe is called with each
+// counter in a process, so nonces from before and after a restart differ in
+// their salt even if the counters overlap. Several counters can be used with
+// the same key, for example one per file, since each has its own salt. Within
+// a process a counter guarantees that its nonces do not repeat; across
+// restarts and counters, the chance of a repeated salt is negligible. The
+// counter should still be persisted, since it reduces the number of nonces
+// that rely on the salt alone.
+//
+// The salt is remembered for the life of the process, keyed by the address
+// of persistedCounter, so the same variable must be passed on every call, and
+// counters should be long-lived rather than allocated per message.
+//
+// SealCrashSafe is safe to call concurrently with the same counter, as long as
+// all accesses to it are atomic. It returns an error if the counter would
+// overflow.
+func SealCrashSafe(message []byte, key nacl.Key, persistedCounter *uint64) ([]byte, nacl.Nonce, error) {
+	var n uint64
+	for {
+		old := atomic.LoadUint64(persistedCounter)
+		if old == ^uint64(0) {
+			return nil, nil, errCounterExhausted
+		}
+		n = old + 1
+		if atomic.CompareAndSwapUint64(persistedCounter, old, n) {
+			break
+		}
+	}
+	salt := bootSalt(persistedCounter)
+	nonce := new([24]byte)
+	copy(nonce[:], salt[:])
+	binary.BigEndian.PutUint64(nonce[16:], n)
+	return Seal(nil, message, nonce, key), nonce, nil
+}
@@ -0,0 +1,100 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+// restart simulates a process restart by forgetting the boot salts.
+func restart() {
+	bootMu.Lock()
+	bootSalts = make(map[*uint64][16]byte)
+	bootMu.Unlock()
+}
+
+func TestSealCrashSafe(t *testing.T) {
+	key := nacl.NewKey()
+	var counter uint64
+	message := []byte("hello world")
+	box, nonce, err := SealCrashSafe(message, key, &counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counter != 1 {
+		t.Errorf("got counter %d, want 1", counter)
+	}
+	opened, ok := Open(nil, box, nonce, key)
+	if !ok || !bytes.Equal(opened, message) {
+		t.Fatal("could not open box")
+	}
+	_, nonce2, err := SealCrashSafe(message, key, &counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *nonce2 == *nonce {
+		t.Error("repeated nonce")
+	}
+	if !bytes.Equal(nonce2[:16], nonce[:16]) {
+		t.Error("salt changed within a process")
+	}
+}
+
+func TestSealCrashSafeRestart(t *testing.T) {
+	key := nacl.NewKey()
+	seen := make(map[[24]byte]bool)
+	// The counter was last flushed at 10, but 5 more boxes were sealed before
+	// the crash.
+	var onDisk uint64 = 10
+	counter := onDisk
+	for i := 0; i < 5; i++ {
+		_, nonce, err := SealCrashSafe(nil, key, &counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[*nonce] = true
+	}
+
+	restart()
+	counter = onDisk
+	for i := 0; i < 5; i++ {
+		_, nonce, err := SealCrashSafe(nil, key, &counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[*nonce] {
+			t.Fatalf("nonce %x repeated after restart", nonce)
+		}
+	}
+}
+
+func TestSealCrashSafeTwoCounters(t *testing.T) {
+	key := nacl.NewKey()
+	// Two counters used with the same key, for example for two files, both
+	// start at zero.
+	var a, b uint64
+	seen := make(map[[24]byte]bool)
+	for i := 0; i < 5; i++ {
+		for _, counter := range []*uint64{&a, &b} {
+			_, nonce, err := SealCrashSafe(nil, key, counter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen[*nonce] {
+				t.Fatalf("nonce %x repeated across counters", nonce)
+			}
+			seen[*nonce] = true
+		}
+	}
+}
+
+func TestSealCrashSafeExhausted(t *testing.T) {
+	counter := ^uint64(0) - 1
+	if _, _, err := SealCrashSafe(nil, nacl.NewKey(), &counter); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := SealCrashSafe(nil, nacl.NewKey(), &counter); err != errCounterExhausted {
+		t.Errorf("expected counter exhausted error, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package secretbox
+
+import (
+	"fmt"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/onetimeauth"
+)
+
+// DiagnoseOpen checks whether box can be opened with nonce and key, without
+// decrypting it, and returns a human-readable reason suitable for logging.
+// The reason never contains any of the plaintext. If the box is valid,
+// DiagnoseOpen returns true and "ok".
+//
+// A Poly1305 tag only says whether the whole box is authentic, so
+// DiagnoseOpen cannot say where a corrupted box was changed, or whether the
+// box, nonce or key is wrong.
+func DiagnoseOpen(box []byte, nonce nacl.Nonce, key nacl.Key) (ok bool, reason string) {
+	switch {
+	case nonce == nil:
+		return false, "nil nonce"
+	case key == nil:
+		return false, "nil key"
+	case len(box) < Overhead:
+		return false, fmt.Sprintf("box too short: %d bytes, need at least %d", len(box), Overhead)
+	}
+	var subKey, poly1305Key [32]byte
+	var counter [16]byte
+	var firstBlock [64]byte
+	setupKeyStream(&subKey, &poly1305Key, &counter, &firstBlock, nonce, key)
+	var tag [onetimeauth.Size]byte
+	copy(tag[:], box)
+	if !onetimeauth.Verify(&tag, box[Overhead:], &poly1305Key) {
+		return false, fmt.Sprintf("authentication failed for %d byte box: corrupted box, or wrong nonce or key", len(box))
+	}
+	return true, "ok"
+}
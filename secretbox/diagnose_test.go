@@ -0,0 +1,38 @@
+package secretbox
+
+import (
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestDiagnoseOpen(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	box := Seal(nil, []byte("hello world"), nonce, key)
+	modified := append([]byte{}, box...)
+	modified[20] ^= 1
+
+	tests := []struct {
+		name   string
+		box    []byte
+		nonce  nacl.Nonce
+		key    nacl.Key
+		ok     bool
+		reason string
+	}{
+		{"valid", box, nonce, key, true, "ok"},
+		{"empty message", Seal(nil, nil, nonce, key), nonce, key, true, "ok"},
+		{"nil nonce", box, nil, key, false, "nil nonce"},
+		{"nil key", box, nonce, nil, false, "nil key"},
+		{"too short", box[:15], nonce, key, false, "box too short: 15 bytes, need at least 16"},
+		{"modified", modified, nonce, key, false, "authentication failed for 27 byte box: corrupted box, or wrong nonce or key"},
+		{"wrong key", box, nonce, nacl.NewKey(), false, "authentication failed for 27 byte box: corrupted box, or wrong nonce or key"},
+	}
+	for _, tt := range tests {
+		ok, reason := DiagnoseOpen(tt.box, tt.nonce, tt.key)
+		if ok != tt.ok || reason != tt.reason {
+			t.Errorf("%s: got %t, %q, want %t, %q", tt.name, ok, reason, tt.ok, tt.reason)
+		}
+	}
+}
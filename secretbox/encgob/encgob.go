@@ -0,0 +1,197 @@
+// Package encgob encrypts gob streams with secretbox.
+//
+// A stream starts with a random 32 byte salt, which is used with the key to
+// derive a key for the stream, so the same key can safely be used for many
+// streams. Each call to Encode writes one frame: the four byte big-endian
+// length of a box, and the box, which holds the gob encoding of the value.
+// The nonce of each box is the frame's sequence number, starting from zero,
+// as a big-endian integer; a stream with frames removed, reordered or
+// modified fails to decode. A stream truncated between frames looks like a
+// stream that ended there.
+package encgob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+const (
+	// SaltSize is the size, in bytes, of the salt at the start of a stream.
+	SaltSize = 32
+
+	// MaxFrameSize is the largest box, in bytes, that a decoder accepts.
+	MaxFrameSize = 64 << 20
+)
+
+var errInvalidInput = errors.New("encgob: Could not decrypt invalid input")
+
+// counter is a frame sequence number, used as a nonce.
+type counter [24]byte
+
+func (c *counter) next() nacl.Nonce {
+	n := new([24]byte)
+	*n = *c
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i]++
+		if c[i] != 0 {
+			break
+		}
+	}
+	return n
+}
+
+// An EncryptedGobEncoder encodes values with gob and writes them to a stream,
+// sealed with secretbox. It is not safe for concurrent use.
+type EncryptedGobEncoder struct {
+	w       io.Writer
+	key     nacl.Key
+	streamK nacl.Key
+	salt    []byte
+	nonce   counter
+	buf     bytes.Buffer
+	enc     *gob.Encoder
+	err     error
+}
+
+// NewEncryptedGobEncoder returns an encoder that writes a stream encrypted
+// with key to w. Nothing is written until the first call to Encode.
+func NewEncryptedGobEncoder(w io.Writer, key nacl.Key) *EncryptedGobEncoder {
+	e := &EncryptedGobEncoder{w: w, key: key}
+	e.enc = gob.NewEncoder(&e.buf)
+	return e
+}
+
+// Encode encodes v with gob and writes it to the stream as a single frame.
+// After an error writing to the underlying writer, every call to Encode
+// returns that error; errors from gob, such as for unsupported types, leave
+// the stream usable.
+func (e *EncryptedGobEncoder) Encode(v interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.streamK == nil {
+		e.salt = make([]byte, SaltSize)
+		randombytes.MustRead(e.salt)
+		e.streamK = auth.Sum(e.salt, e.key)
+	}
+	e.buf.Reset()
+	encErr := e.enc.Encode(v)
+	if encErr != nil && e.buf.Len() == 0 {
+		return encErr
+	}
+	// If gob failed after writing type definitions, they are still written
+	// out, since the gob encoder will not send them again.
+	if e.buf.Len()+secretbox.Overhead > MaxFrameSize {
+		e.err = fmt.Errorf("encgob: encoded value is larger than %d bytes", MaxFrameSize)
+		return e.err
+	}
+	// The salt is written with the first frame.
+	out := e.salt
+	e.salt = nil
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(e.buf.Len()+secretbox.Overhead))
+	out = append(out, length[:]...)
+	out = secretbox.Seal(out, e.buf.Bytes(), e.nonce.next(), e.streamK)
+	if _, e.err = e.w.Write(out); e.err != nil {
+		return e.err
+	}
+	return encErr
+}
+
+// frameReader returns the plaintext of the frames of a stream in order.
+type frameReader struct {
+	r       io.Reader
+	key     nacl.Key
+	streamK nacl.Key
+	nonce   counter
+	buf     []byte
+	plain   []byte
+}
+
+func (f *frameReader) readFrame() error {
+	if f.streamK == nil {
+		salt := make([]byte, SaltSize)
+		if _, err := io.ReadFull(f.r, salt); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return errInvalidInput
+			}
+			return err
+		}
+		f.streamK = auth.Sum(salt, f.key)
+	}
+	var length [4]byte
+	if _, err := io.ReadFull(f.r, length[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return errInvalidInput
+		}
+		return err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n < secretbox.Overhead || n > MaxFrameSize {
+		return errInvalidInput
+	}
+	if cap(f.buf) < int(n) {
+		f.buf = make([]byte, n)
+	}
+	f.buf = f.buf[:n]
+	if _, err := io.ReadFull(f.r, f.buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return errInvalidInput
+		}
+		return err
+	}
+	plain, ok := secretbox.Open(f.plain[:0], f.buf, f.nonce.next(), f.streamK)
+	if !ok {
+		return errInvalidInput
+	}
+	f.plain = plain
+	return nil
+}
+
+func (f *frameReader) Read(p []byte) (int, error) {
+	for len(f.plain) == 0 {
+		if err := f.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.plain)
+	f.plain = f.plain[n:]
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader, so gob does not add its own buffering.
+func (f *frameReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := f.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// An EncryptedGobDecoder reads values written by an EncryptedGobEncoder. It
+// is not safe for concurrent use.
+type EncryptedGobDecoder struct {
+	dec *gob.Decoder
+}
+
+// NewEncryptedGobDecoder returns a decoder that reads a stream encrypted with
+// key from r.
+func NewEncryptedGobDecoder(r io.Reader, key nacl.Key) *EncryptedGobDecoder {
+	return &EncryptedGobDecoder{dec: gob.NewDecoder(&frameReader{r: r, key: key})}
+}
+
+// Decode reads the next value from the stream and stores it in v, as
+// gob.Decoder.Decode does. At the end of the stream, Decode returns io.EOF.
+// If the stream has been modified, Decode returns an error.
+func (d *EncryptedGobDecoder) Decode(v interface{}) error {
+	return d.dec.Decode(v)
+}
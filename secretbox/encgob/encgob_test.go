@@ -0,0 +1,160 @@
+package encgob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+type record struct {
+	Name  string
+	Count int
+	Tags  []string
+}
+
+var records = []record{
+	{"alice", 1, []string{"admin"}},
+	{"bob", 2, nil},
+	{"carol", 3, []string{"a", "b", "c"}},
+}
+
+func encode(t *testing.T, key nacl.Key, values ...interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := NewEncryptedGobEncoder(&buf, key)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeDecode(t *testing.T) {
+	key := nacl.NewKey()
+	stream := encode(t, key, records[0], records[1], records[2], "a string", 42)
+	if bytes.Contains(stream, []byte("alice")) {
+		t.Errorf("stream contains plaintext")
+	}
+
+	dec := NewEncryptedGobDecoder(bytes.NewReader(stream), key)
+	for _, want := range records {
+		var got record
+		if err := dec.Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+	var s string
+	if err := dec.Decode(&s); err != nil || s != "a string" {
+		t.Errorf("got %q, %v, want %q", s, err, "a string")
+	}
+	var n int
+	if err := dec.Decode(&n); err != nil || n != 42 {
+		t.Errorf("got %d, %v, want 42", n, err)
+	}
+	if err := dec.Decode(&n); err != io.EOF {
+		t.Errorf("got error %v at end of stream, want io.EOF", err)
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	var r record
+	if err := NewEncryptedGobDecoder(bytes.NewReader(nil), nacl.NewKey()).Decode(&r); err != io.EOF {
+		t.Errorf("got error %v, want io.EOF", err)
+	}
+}
+
+func TestEncodeUnsupportedType(t *testing.T) {
+	key := nacl.NewKey()
+	var buf bytes.Buffer
+	enc := NewEncryptedGobEncoder(&buf, key)
+	if err := enc.Encode(make(chan int)); err == nil {
+		t.Fatal("expected error encoding a channel")
+	}
+	if err := enc.Encode(records[0]); err != nil {
+		t.Fatal(err)
+	}
+	var got record
+	if err := NewEncryptedGobDecoder(&buf, key).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records[0]) {
+		t.Errorf("got %+v, want %+v", got, records[0])
+	}
+}
+
+func decodeAll(stream []byte, key nacl.Key) error {
+	dec := NewEncryptedGobDecoder(bytes.NewReader(stream), key)
+	for {
+		var r record
+		if err := dec.Decode(&r); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func TestDecodeModified(t *testing.T) {
+	key := nacl.NewKey()
+	stream := encode(t, key, records[0], records[1], records[2])
+	if err := decodeAll(stream, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := decodeAll(stream, nacl.NewKey()); err == nil {
+		t.Error("decoded stream with wrong key")
+	}
+	for i := range stream {
+		modified := append([]byte{}, stream...)
+		modified[i] ^= 1
+		if err := decodeAll(modified, key); err == nil {
+			t.Fatalf("decoded stream modified at byte %d", i)
+		}
+	}
+	if err := decodeAll(stream[:len(stream)-1], key); err == nil {
+		t.Error("decoded truncated stream")
+	}
+}
+
+func TestDecodeReordered(t *testing.T) {
+	key := nacl.NewKey()
+	first := encode(t, key, records[0])
+	stream := encode(t, key, records[0], records[1], records[2])
+	// Skip the salt, then find the boundaries of the three frames.
+	var frames [][]byte
+	rest := stream[SaltSize:]
+	for len(rest) > 0 {
+		n := 4 + int(binary.BigEndian.Uint32(rest))
+		frames = append(frames, rest[:n])
+		rest = rest[n:]
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(frames))
+	}
+	reordered := append([]byte{}, stream[:SaltSize]...)
+	reordered = append(reordered, frames[0]...)
+	reordered = append(reordered, frames[2]...)
+	reordered = append(reordered, frames[1]...)
+	if err := decodeAll(reordered, key); err == nil {
+		t.Error("decoded stream with reordered frames")
+	}
+	dropped := append([]byte{}, stream[:SaltSize]...)
+	dropped = append(dropped, frames[0]...)
+	dropped = append(dropped, frames[2]...)
+	if err := decodeAll(dropped, key); err == nil {
+		t.Error("decoded stream with a frame removed")
+	}
+	// A frame from another stream does not decode, even at the same position.
+	spliced := append([]byte{}, stream[:SaltSize]...)
+	spliced = append(spliced, first[SaltSize:]...)
+	if err := decodeAll(spliced, key); err == nil {
+		t.Error("decoded frame from another stream")
+	}
+}
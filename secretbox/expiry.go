@@ -0,0 +1,64 @@
+package secretbox
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+// ErrExpired is returned by OpenWithExpiry when a box has expired.
+var ErrExpired = errors.New("secretbox: box has expired")
+
+var errExpiryRange = errors.New("secretbox: expiry time out of range")
+
+// now is replaced in tests.
+var now = time.Now
+
+var (
+	minExpiry = time.Unix(0, math.MinInt64)
+	maxExpiry = time.Unix(0, math.MaxInt64)
+)
+
+// expirySize is the size of the expiry time stored at the start of the
+// sealed message.
+const expirySize = 8
+
+// SealWithExpiry encrypts message using key, along with the time it expires.
+// A random nonce is generated and prepended to the output, as with EasySeal.
+// The expiry is stored in the encrypted part of the box as a big-endian count
+// of nanoseconds since the Unix epoch, so it is authenticated and cannot be
+// read or changed without the key. The output will be Overhead+32 bytes
+// longer than message.
+//
+// SealWithExpiry returns an error if expiresAt cannot be represented, which
+// is the case for times before the year 1678 or after 2262.
+func SealWithExpiry(message []byte, expiresAt time.Time, key nacl.Key) ([]byte, error) {
+	if expiresAt.Before(minExpiry) || expiresAt.After(maxExpiry) {
+		return nil, errExpiryRange
+	}
+	plaintext := make([]byte, expirySize+len(message))
+	binary.BigEndian.PutUint64(plaintext, uint64(expiresAt.UnixNano()))
+	copy(plaintext[expirySize:], message)
+	return EasySeal(plaintext, key), nil
+}
+
+// OpenWithExpiry decrypts a box produced by SealWithExpiry. If the box is
+// valid but the current time is after its expiry, OpenWithExpiry returns
+// ErrExpired and no plaintext.
+func OpenWithExpiry(box []byte, key nacl.Key) ([]byte, error) {
+	plaintext, err := EasyOpen(box, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(plaintext) < expirySize {
+		return nil, errInvalidInput
+	}
+	expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(plaintext)))
+	if now().After(expiresAt) {
+		return nil, ErrExpired
+	}
+	return plaintext[expirySize:], nil
+}
@@ -0,0 +1,72 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+func setNow(t time.Time) func() {
+	now = func() time.Time { return t }
+	return func() { now = time.Now }
+}
+
+func TestExpiry(t *testing.T) {
+	key := nacl.NewKey()
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	message := []byte("launch code")
+	box, err := SealWithExpiry(message, expiresAt, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(box) != len(message)+Overhead+32 {
+		t.Errorf("got length %d, want %d", len(box), len(message)+Overhead+32)
+	}
+
+	defer setNow(expiresAt.Add(-time.Hour))()
+	opened, err := OpenWithExpiry(box, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, message) {
+		t.Errorf("got %q, want %q", opened, message)
+	}
+
+	setNow(expiresAt)
+	if _, err := OpenWithExpiry(box, key); err != nil {
+		t.Errorf("could not open box at its expiry time: %v", err)
+	}
+	setNow(expiresAt.Add(time.Nanosecond))
+	if opened, err := OpenWithExpiry(box, key); err != ErrExpired || opened != nil {
+		t.Errorf("expected ErrExpired just after expiry, got %q, %v", opened, err)
+	}
+}
+
+func TestExpiryTampered(t *testing.T) {
+	key := nacl.NewKey()
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	box, err := SealWithExpiry([]byte("launch code"), expiresAt, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setNow(expiresAt.Add(time.Hour))()
+	// Flip a bit in the expiry, which is the first byte of ciphertext after
+	// the nonce and tag, to try to push it into the future.
+	box[24+Overhead] ^= 0x40
+	if _, err := OpenWithExpiry(box, key); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestExpiryRange(t *testing.T) {
+	key := nacl.NewKey()
+	if _, err := SealWithExpiry(nil, time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC), key); err != errExpiryRange {
+		t.Errorf("expected range error, got %v", err)
+	}
+	// A box too short to hold an expiry must not open.
+	if _, err := OpenWithExpiry(EasySeal([]byte("short"), key), key); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
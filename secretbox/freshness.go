@@ -0,0 +1,46 @@
+package secretbox
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+
+	"github.com/kevinburke/nacl"
+)
+
+// SequenceNonce returns the nonce for sequence number seq: 16 zero bytes
+// followed by seq as an 8 byte big-endian integer. Senders seal message seq
+// with Seal and this nonce, for OpenWithFreshness to open. Each sequence
+// number must be used only once with a key.
+func SequenceNonce(seq uint64) nacl.Nonce {
+	nonce := new([24]byte)
+	binary.BigEndian.PutUint64(nonce[16:], seq)
+	return nonce
+}
+
+// OpenWithFreshness opens box, sealed with the nonce SequenceNonce(seq), and
+// rejects it if seq is more than maxBehind below *lastSeen, the highest
+// sequence number opened so far. When sequence numbers are sent at a known
+// rate, this bounds how old a box can be, and so how long a captured box can
+// be replayed. If the box opens and seq is higher than *lastSeen, *lastSeen is
+// set to seq.
+//
+// Boxes within the window can still be replayed; callers that need to reject
+// every replay must also track which sequence numbers they have seen.
+// OpenWithFreshness is safe to call concurrently with the same lastSeen, as
+// long as all accesses to it are atomic.
+func OpenWithFreshness(box []byte, seq uint64, maxBehind uint64, lastSeen *uint64, key nacl.Key) ([]byte, bool) {
+	if last := atomic.LoadUint64(lastSeen); last > maxBehind && seq < last-maxBehind {
+		return nil, false
+	}
+	message, ok := Open(nil, box, SequenceNonce(seq), key)
+	if !ok {
+		return nil, false
+	}
+	for {
+		last := atomic.LoadUint64(lastSeen)
+		if seq <= last || atomic.CompareAndSwapUint64(lastSeen, last, seq) {
+			break
+		}
+	}
+	return message, true
+}
@@ -0,0 +1,82 @@
+package secretbox
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func sealSeq(seq uint64, key nacl.Key) []byte {
+	return Seal(nil, []byte(fmt.Sprintf("reading %d", seq)), SequenceNonce(seq), key)
+}
+
+func TestOpenWithFreshness(t *testing.T) {
+	key := nacl.NewKey()
+	var lastSeen uint64
+
+	// Advancing sequence numbers open and move lastSeen forward.
+	for _, seq := range []uint64{1, 2, 5, 100} {
+		message, ok := OpenWithFreshness(sealSeq(seq, key), seq, 10, &lastSeen, key)
+		if !ok {
+			t.Fatalf("could not open sequence %d", seq)
+		}
+		if want := fmt.Sprintf("reading %d", seq); string(message) != want {
+			t.Errorf("got %q, want %q", message, want)
+		}
+		if lastSeen != seq {
+			t.Errorf("after %d: got lastSeen %d", seq, lastSeen)
+		}
+	}
+
+	// In-window sequence numbers open without moving lastSeen back.
+	for _, seq := range []uint64{90, 95, 99, 100} {
+		if _, ok := OpenWithFreshness(sealSeq(seq, key), seq, 10, &lastSeen, key); !ok {
+			t.Errorf("could not open in-window sequence %d", seq)
+		}
+	}
+	if lastSeen != 100 {
+		t.Errorf("got lastSeen %d, want 100", lastSeen)
+	}
+
+	// Stale sequence numbers are rejected.
+	for _, seq := range []uint64{0, 1, 89} {
+		if _, ok := OpenWithFreshness(sealSeq(seq, key), seq, 10, &lastSeen, key); ok {
+			t.Errorf("opened stale sequence %d", seq)
+		}
+	}
+}
+
+func TestOpenWithFreshnessInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	lastSeen := uint64(5)
+	box := sealSeq(7, key)
+	// A box opened with the wrong sequence number fails, and does not move
+	// lastSeen.
+	if _, ok := OpenWithFreshness(box, 1000, 10, &lastSeen, key); ok {
+		t.Error("opened box with the wrong sequence number")
+	}
+	if _, ok := OpenWithFreshness(box, 7, 10, &lastSeen, nacl.NewKey()); ok {
+		t.Error("opened box with the wrong key")
+	}
+	if lastSeen != 5 {
+		t.Errorf("failed opens changed lastSeen to %d", lastSeen)
+	}
+	message, ok := OpenWithFreshness(box, 7, 0, &lastSeen, key)
+	if !ok || !bytes.Equal(message, []byte("reading 7")) {
+		t.Errorf("got %q, %t", message, ok)
+	}
+	// With maxBehind 0, only sequence numbers at or above lastSeen open.
+	if _, ok := OpenWithFreshness(sealSeq(6, key), 6, 0, &lastSeen, key); ok {
+		t.Error("opened sequence below lastSeen with maxBehind 0")
+	}
+}
+
+func TestSequenceNonce(t *testing.T) {
+	nonce := SequenceNonce(0x0102030405060708)
+	want := [24]byte{16: 1, 2, 3, 4, 5, 6, 7, 8}
+	if *nonce != want {
+		t.Errorf("got nonce %x, want %x", nonce[:], want[:])
+	}
+}
@@ -0,0 +1,81 @@
+package gcm
+
+import (
+	"compress/zlib"
+	"io"
+
+	"github.com/kevinburke/nacl"
+)
+
+type compressWriter struct {
+	z   *zlib.Writer
+	enc io.WriteCloser
+}
+
+// NewCompressEncryptWriter returns an io.WriteCloser that compresses data
+// written to it with zlib at the given level, then encrypts it as with
+// NewWriter. It returns an error if level is not a valid zlib compression
+// level. Callers must call Close to flush the compressed data and write the
+// final chunk; Close does not close w.
+//
+// Compressing data before encrypting it makes the length of the ciphertext
+// depend on the contents of the plaintext. If an attacker can influence part
+// of the plaintext, and it also contains a secret, they may be able to learn
+// the secret by watching the length change, as in the CRIME attack on TLS.
+func NewCompressEncryptWriter(w io.Writer, key nacl.Key, level int) (io.WriteCloser, error) {
+	enc := NewWriter(w, key)
+	z, err := zlib.NewWriterLevel(enc, level)
+	if err != nil {
+		return nil, err
+	}
+	return &compressWriter{z: z, enc: enc}, nil
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	return w.z.Write(p)
+}
+
+func (w *compressWriter) Close() error {
+	if err := w.z.Close(); err != nil {
+		return err
+	}
+	return w.enc.Close()
+}
+
+type decompressReader struct {
+	dec io.ReadCloser
+	z   io.ReadCloser
+	err error
+}
+
+// NewDecryptDecompressReader returns an io.ReadCloser that decrypts a stream
+// written by a writer created with NewCompressEncryptWriter and key, and
+// decompresses it. Each chunk is authenticated before it is decompressed, so
+// the decompressor never sees modified data; if the stream was modified or
+// truncated, Read returns the same error as a reader created with NewReader.
+// Close does not close r.
+func NewDecryptDecompressReader(r io.Reader, key nacl.Key) io.ReadCloser {
+	return &decompressReader{dec: NewReader(r, key)}
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	// zlib.NewReader reads the zlib header, so wait until the first Read to
+	// create it.
+	if r.z == nil {
+		r.z, r.err = zlib.NewReader(r.dec)
+		if r.err != nil {
+			return 0, r.err
+		}
+	}
+	return r.z.Read(p)
+}
+
+func (r *decompressReader) Close() error {
+	if r.z != nil {
+		r.z.Close()
+	}
+	return r.dec.Close()
+}
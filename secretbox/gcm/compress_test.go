@@ -0,0 +1,73 @@
+package gcm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func compressEncrypt(t *testing.T, plaintext []byte, key nacl.Key) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewCompressEncryptWriter(&buf, key, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompressEncrypt(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := bytes.Repeat([]byte("the same log line over and over\n"), 10000)
+	ciphertext := compressEncrypt(t, plaintext, key)
+	if len(ciphertext) >= len(plaintext)/10 {
+		t.Errorf("got %d bytes of ciphertext for %d bytes of plaintext, want compression", len(ciphertext), len(plaintext))
+	}
+	r := NewDecryptDecompressReader(bytes.NewReader(ciphertext), key)
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("decompressed plaintext does not match")
+	}
+
+	got, err = ioutil.ReadAll(NewDecryptDecompressReader(bytes.NewReader(compressEncrypt(t, nil, key)), key))
+	if err != nil || len(got) != 0 {
+		t.Errorf("empty stream: got %q, %v", got, err)
+	}
+}
+
+func TestDecryptDecompressErrors(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := bytes.Repeat([]byte("hello "), 1000)
+	ciphertext := compressEncrypt(t, plaintext, key)
+
+	// The error must come from decryption, not from inflating garbage.
+	if _, err := ioutil.ReadAll(NewDecryptDecompressReader(bytes.NewReader(ciphertext), nacl.NewKey())); err != errInvalidInput {
+		t.Errorf("wrong key: expected invalid input error, got %v", err)
+	}
+	bad := append([]byte{}, ciphertext...)
+	bad[len(bad)-1] ^= 0x01
+	if _, err := ioutil.ReadAll(NewDecryptDecompressReader(bytes.NewReader(bad), key)); err != errInvalidInput {
+		t.Errorf("modified stream: expected invalid input error, got %v", err)
+	}
+	if _, err := ioutil.ReadAll(NewDecryptDecompressReader(bytes.NewReader(ciphertext[:len(ciphertext)-1]), key)); err != errInvalidInput {
+		t.Errorf("truncated stream: expected invalid input error, got %v", err)
+	}
+
+	if _, err := NewCompressEncryptWriter(ioutil.Discard, key, 42); err == nil {
+		t.Error("expected error for invalid compression level")
+	}
+}
@@ -0,0 +1,284 @@
+// Package gcm encrypts and decrypts streams of data with AES-256-GCM.
+//
+// A stream starts with a random 32 byte salt. The salt and the key are used to
+// derive a key for the stream, so the same key can safely be used to encrypt
+// many streams. The data follows, split into chunks of ChunkSize bytes (the
+// last chunk may be shorter, or empty), each sealed with AES-256-GCM and
+// followed by a 16 byte tag. The 12 byte nonce for each chunk is an 11 byte
+// big-endian counter followed by a byte that is 1 for the last chunk and 0
+// otherwise, so a stream that has been truncated, reordered or extended fails
+// to decrypt.
+//
+// On platforms with hardware support for AES (see HardwareAccelerated), this
+// is considerably faster than XSalsa20 and Poly1305.
+package gcm
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/randombytes"
+)
+
+const (
+	// ChunkSize is the size, in bytes, of each chunk of plaintext.
+	ChunkSize = 64 * 1024
+
+	// SaltSize is the size, in bytes, of the salt at the start of a stream.
+	SaltSize = 32
+
+	// Overhead is the number of bytes of overhead per chunk.
+	Overhead = 16
+)
+
+var (
+	errInvalidInput = errors.New("gcm: Could not decrypt invalid input")
+	errClosed       = errors.New("gcm: write to closed Writer")
+	errNotSeeker    = errors.New("gcm: VerifyFirst requires an io.Seeker")
+)
+
+func newAEAD(salt []byte, key nacl.Key) cipher.AEAD {
+	streamKey := auth.Sum(salt, key)
+	block, err := aes.NewCipher(streamKey[:])
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// nonce is a 12 byte chunk counter, incremented after each chunk.
+type nonce [12]byte
+
+func (n *nonce) next(final bool) []byte {
+	out := make([]byte, 12)
+	copy(out, n[:11])
+	if final {
+		out[11] = 1
+	}
+	for i := 10; i >= 0; i-- {
+		n[i]++
+		if n[i] != 0 {
+			break
+		}
+	}
+	return out
+}
+
+type writer struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	nonce  nonce
+	buf    []byte
+	err    error
+	closed bool
+}
+
+// NewWriter returns an io.WriteCloser that encrypts data written to it with
+// key, and writes the encrypted stream to w. Data is buffered until a full
+// chunk is available. Callers must call Close to write the final chunk; Close
+// does not close w.
+func NewWriter(w io.Writer, key nacl.Key) io.WriteCloser {
+	salt := make([]byte, SaltSize)
+	randombytes.MustRead(salt)
+	wr := &writer{
+		w:    w,
+		aead: newAEAD(salt, key),
+		buf:  make([]byte, 0, ChunkSize+Overhead),
+	}
+	_, wr.err = w.Write(salt)
+	return wr
+}
+
+func (w *writer) seal(final bool) error {
+	w.buf = w.aead.Seal(w.buf[:0], w.nonce.next(final), w.buf, nil)
+	_, err := w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// Only seal a full chunk once more data arrives, so the final chunk
+		// can always be marked as such in Close.
+		if len(w.buf) == ChunkSize {
+			if w.err = w.seal(false); w.err != nil {
+				return n, w.err
+			}
+		}
+		m := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		n += m
+		p = p[m:]
+	}
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.seal(true)
+	return w.err
+}
+
+type reader struct {
+	src         io.Reader
+	r           *bufio.Reader
+	key         nacl.Key
+	aead        cipher.AEAD
+	nonce       nonce
+	buf         []byte
+	plain       []byte
+	err         error
+	verifyFirst bool
+}
+
+// ReaderOptions configures a reader created with NewReaderWithOptions.
+type ReaderOptions struct {
+	// VerifyFirst authenticates the entire stream before any plaintext is
+	// returned. The input is read twice: once to authenticate every chunk,
+	// and again to decrypt it, so it must implement io.Seeker. The first call
+	// to Read performs the verification pass.
+	VerifyFirst bool
+}
+
+// NewReader returns an io.ReadCloser that decrypts a stream written by a
+// Writer created with NewWriter and key. Each chunk is authenticated before
+// any of its plaintext is returned. If the stream was modified or truncated,
+// Read returns an error; plaintext from earlier chunks may already have been
+// returned. Close does not close r.
+func NewReader(r io.Reader, key nacl.Key) io.ReadCloser {
+	return NewReaderWithOptions(r, key, nil)
+}
+
+// NewReaderWithOptions is like NewReader, but configured with opts, which may
+// be nil.
+//
+// If opts.VerifyFirst is set, no plaintext is returned unless the whole
+// stream is valid, at the cost of reading the input twice. If r does not
+// implement io.Seeker, Read returns an error.
+func NewReaderWithOptions(r io.Reader, key nacl.Key, opts *ReaderOptions) io.ReadCloser {
+	rd := &reader{
+		src: r,
+		r:   bufio.NewReaderSize(r, ChunkSize+Overhead),
+		key: key,
+		buf: make([]byte, ChunkSize+Overhead),
+	}
+	if opts != nil {
+		rd.verifyFirst = opts.VerifyFirst
+	}
+	return rd
+}
+
+// verify authenticates every chunk from the current position of the input to
+// the end of the stream, then seeks back to the current position.
+func (r *reader) verify() error {
+	seeker, ok := r.src.(io.Seeker)
+	if !ok {
+		return errNotSeeker
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	v := &reader{
+		r:   r.r,
+		key: r.key,
+		buf: r.buf,
+	}
+	for {
+		err := v.readChunk()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+	r.r.Reset(r.src)
+	return nil
+}
+
+// readChunk reads, authenticates and decrypts the next chunk.
+func (r *reader) readChunk() error {
+	if r.aead == nil {
+		salt := make([]byte, SaltSize)
+		if _, err := io.ReadFull(r.r, salt); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return errInvalidInput
+			}
+			return err
+		}
+		r.aead = newAEAD(salt, r.key)
+	}
+	n, err := io.ReadFull(r.r, r.buf)
+	final := false
+	switch err {
+	case nil:
+		// A full chunk is the final chunk if nothing follows it.
+		if _, err := r.r.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		final = true
+	default:
+		return err
+	}
+	if n < Overhead {
+		return errInvalidInput
+	}
+	plain, err := r.aead.Open(r.buf[:0], r.nonce.next(final), r.buf[:n], nil)
+	if err != nil {
+		return errInvalidInput
+	}
+	r.plain = plain
+	if final {
+		return io.EOF
+	}
+	return nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.verifyFirst {
+		r.verifyFirst = false
+		r.err = r.verify()
+	}
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.readChunk()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *reader) Close() error {
+	return nil
+}
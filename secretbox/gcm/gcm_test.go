@@ -0,0 +1,229 @@
+package gcm
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+)
+
+func encrypt(t testing.TB, plaintext []byte, key nacl.Key) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, key)
+	// Write in odd-sized pieces to exercise buffering.
+	for p := plaintext; len(p) > 0; {
+		n := 1000
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatal(err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(ciphertext []byte, key nacl.Key) ([]byte, error) {
+	return ioutil.ReadAll(NewReader(bytes.NewReader(ciphertext), key))
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := nacl.NewKey()
+	for _, size := range []int{0, 1, 1000, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 17} {
+		plaintext := make([]byte, size)
+		randombytes.MustRead(plaintext)
+		ciphertext := encrypt(t, plaintext, key)
+		chunks := size/ChunkSize + 1
+		if size > 0 && size%ChunkSize == 0 {
+			chunks--
+		}
+		if want := SaltSize + size + chunks*Overhead; len(ciphertext) != want {
+			t.Errorf("%d: got ciphertext length %d, want %d", size, len(ciphertext), want)
+		}
+		got, err := decrypt(ciphertext, key)
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("%d: plaintext does not round trip", size)
+		}
+	}
+}
+
+func TestSalt(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := []byte("hello world")
+	a := encrypt(t, plaintext, key)
+	b := encrypt(t, plaintext, key)
+	if bytes.Equal(a[SaltSize:], b[SaltSize:]) {
+		t.Errorf("two streams with the same key produced the same ciphertext")
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := make([]byte, 3*ChunkSize+100)
+	ciphertext := encrypt(t, plaintext, key)
+	for _, n := range []int{
+		SaltSize + 2*(ChunkSize+Overhead), // at a chunk boundary
+		SaltSize + ChunkSize + Overhead,   // at a chunk boundary
+		len(ciphertext) - 1,
+		SaltSize,
+		SaltSize - 1,
+		0,
+	} {
+		if _, err := decrypt(ciphertext[:n], key); err != errInvalidInput {
+			t.Errorf("truncated to %d bytes: expected invalid input error, got %v", n, err)
+		}
+	}
+}
+
+func TestTruncatedExactChunks(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := make([]byte, 2*ChunkSize)
+	ciphertext := encrypt(t, plaintext, key)
+	n := SaltSize + ChunkSize + Overhead
+	if _, err := decrypt(ciphertext[:n], key); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestModified(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := make([]byte, 2*ChunkSize+100)
+	ciphertext := encrypt(t, plaintext, key)
+	for _, i := range []int{0, SaltSize, SaltSize + ChunkSize + 5, len(ciphertext) - 1} {
+		ciphertext[i] ^= 0x01
+		if _, err := decrypt(ciphertext, key); err != errInvalidInput {
+			t.Errorf("modified byte %d: expected invalid input error, got %v", i, err)
+		}
+		ciphertext[i] ^= 0x01
+	}
+	// Swap the first two chunks.
+	swapped := append([]byte{}, ciphertext...)
+	first := swapped[SaltSize : SaltSize+ChunkSize+Overhead]
+	second := swapped[SaltSize+ChunkSize+Overhead : SaltSize+2*(ChunkSize+Overhead)]
+	tmp := append([]byte{}, first...)
+	copy(first, second)
+	copy(second, tmp)
+	if _, err := decrypt(swapped, key); err != errInvalidInput {
+		t.Errorf("swapped chunks: expected invalid input error, got %v", err)
+	}
+	if _, err := decrypt(ciphertext, nacl.NewKey()); err != errInvalidInput {
+		t.Errorf("wrong key: expected invalid input error, got %v", err)
+	}
+}
+
+func TestPartialReads(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := make([]byte, 2*ChunkSize+100)
+	randombytes.MustRead(plaintext)
+	ciphertext := encrypt(t, plaintext, key)
+	r := NewReader(bytes.NewReader(ciphertext), key)
+	var got []byte
+	buf := make([]byte, 777)
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("plaintext does not round trip")
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	w := NewWriter(ioutil.Discard, nacl.NewKey())
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("hello")); err != errClosed {
+		t.Errorf("expected closed error, got %v", err)
+	}
+}
+
+func TestVerifyFirst(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := make([]byte, 3*ChunkSize+17)
+	randombytes.MustRead(plaintext)
+	ciphertext := encrypt(t, plaintext, key)
+
+	// Bytes before the stream should be left alone.
+	prefix := []byte("header")
+	input := bytes.NewReader(append(append([]byte{}, prefix...), ciphertext...))
+	input.Seek(int64(len(prefix)), io.SeekStart)
+	r := NewReaderWithOptions(input, key, &ReaderOptions{VerifyFirst: true})
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("plaintext does not match after verification pass")
+	}
+
+	// Corrupting the last chunk must prevent any plaintext being written.
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	var out bytes.Buffer
+	r = NewReaderWithOptions(bytes.NewReader(ciphertext), key, &ReaderOptions{VerifyFirst: true})
+	if _, err := io.Copy(&out, r); err != errInvalidInput {
+		t.Fatalf("expected invalid input error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %d bytes of unverified plaintext", out.Len())
+	}
+
+	// Without VerifyFirst, the earlier chunks are written.
+	out.Reset()
+	if _, err := io.Copy(&out, NewReader(bytes.NewReader(ciphertext), key)); err != errInvalidInput {
+		t.Fatalf("expected invalid input error, got %v", err)
+	}
+	if out.Len() != 3*ChunkSize {
+		t.Errorf("got %d bytes of plaintext, want %d", out.Len(), 3*ChunkSize)
+	}
+}
+
+func TestVerifyFirstTruncated(t *testing.T) {
+	key := nacl.NewKey()
+	ciphertext := encrypt(t, make([]byte, 2*ChunkSize+1), key)
+	var out bytes.Buffer
+	r := NewReaderWithOptions(bytes.NewReader(ciphertext[:len(ciphertext)-ChunkSize/2]), key, &ReaderOptions{VerifyFirst: true})
+	if _, err := io.Copy(&out, r); err != errInvalidInput {
+		t.Fatalf("expected invalid input error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %d bytes of unverified plaintext", out.Len())
+	}
+}
+
+func TestVerifyFirstNotSeeker(t *testing.T) {
+	key := nacl.NewKey()
+	ciphertext := encrypt(t, []byte("hello"), key)
+	r := NewReaderWithOptions(ioutil.NopCloser(bytes.NewReader(ciphertext)), key, &ReaderOptions{VerifyFirst: true})
+	if _, err := ioutil.ReadAll(r); err != errNotSeeker {
+		t.Errorf("expected not seeker error, got %v", err)
+	}
+}
+
+func BenchmarkWriter(b *testing.B) {
+	key := nacl.NewKey()
+	buf := make([]byte, 1<<20)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := NewWriter(ioutil.Discard, key)
+		w.Write(buf)
+		w.Close()
+	}
+}
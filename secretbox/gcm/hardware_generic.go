@@ -0,0 +1,10 @@
+//go:build !amd64 && !arm64 && !ppc64le && !s390x
+// +build !amd64,!arm64,!ppc64le,!s390x
+
+package gcm
+
+// HardwareAccelerated reports whether the Go standard library implements
+// AES-GCM with hardware instructions on this architecture. Callers choosing
+// between this package and the XSalsa20-based secretbox can use it to pick the
+// faster option.
+const HardwareAccelerated = false
@@ -0,0 +1,69 @@
+package secretbox
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/onetimeauth"
+)
+
+// A HeaderMACer authenticates messages made up of a fixed size header and a
+// variable size body, for protocols that send the header in cleartext. The
+// header and body are authenticated together, so neither can be changed or
+// moved to another message.
+type HeaderMACer struct {
+	headerSize int
+}
+
+// NewAuthenticatedHeader returns a HeaderMACer for headers of headerSize
+// bytes. It panics if headerSize is negative.
+func NewAuthenticatedHeader(headerSize int) *HeaderMACer {
+	if headerSize < 0 {
+		panic("secretbox: negative header size")
+	}
+	return &HeaderMACer{headerSize: headerSize}
+}
+
+// headerKeyLabel separates the key for header MACs from the key passed in,
+// which may also be used with Seal.
+const headerKeyLabel = "nacl secretbox header"
+
+// polyKey derives the Poly1305 key for nonce and key. It is derived as Seal
+// derives its own, but from a separate key, since Seal's tag is Poly1305 over
+// the bare ciphertext: under Seal's one-time key, a MAC followed by the header
+// and body would be a valid box.
+func polyKey(poly1305Key nacl.Key, nonce nacl.Nonce, key nacl.Key) {
+	var subKey [32]byte
+	var counter [16]byte
+	var firstBlock [64]byte
+	setupKeyStream(&subKey, poly1305Key, &counter, &firstBlock, nonce, auth.Sum([]byte(headerKeyLabel), key))
+}
+
+// MAC returns a Poly1305 tag for header followed by body, using a one-time key
+// derived from key and nonce with XSalsa20. The key and nonce pair must be
+// unique for each message. The one-time key differs from the one Seal uses
+// for the same key and nonce, so a MAC is never a valid box. MAC panics if
+// header is not the size passed to NewAuthenticatedHeader.
+func (h *HeaderMACer) MAC(header, body []byte, key nacl.Key, nonce nacl.Nonce) [16]byte {
+	if len(header) != h.headerSize {
+		panic("secretbox: incorrect header size")
+	}
+	var poly1305Key [32]byte
+	polyKey(&poly1305Key, nonce, key)
+	m := make([]byte, 0, len(header)+len(body))
+	m = append(append(m, header...), body...)
+	return *onetimeauth.Sum(m, &poly1305Key)
+}
+
+// Verify reports whether mac is a valid tag for header and body under key and
+// nonce. It returns false if header is not the size passed to
+// NewAuthenticatedHeader.
+func (h *HeaderMACer) Verify(mac [16]byte, header, body []byte, key nacl.Key, nonce nacl.Nonce) bool {
+	if len(header) != h.headerSize {
+		return false
+	}
+	var poly1305Key [32]byte
+	polyKey(&poly1305Key, nonce, key)
+	m := make([]byte, 0, len(header)+len(body))
+	m = append(append(m, header...), body...)
+	return onetimeauth.Verify(&mac, m, &poly1305Key)
+}
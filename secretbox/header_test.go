@@ -0,0 +1,52 @@
+package secretbox
+
+import (
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestHeaderMAC(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	h := NewAuthenticatedHeader(4)
+	header := []byte{0x01, 0x00, 0x00, 0x05}
+	body := []byte("hello")
+	mac := h.MAC(header, body, key, nonce)
+	if !h.Verify(mac, header, body, key, nonce) {
+		t.Fatal("could not verify MAC")
+	}
+
+	// A MAC followed by the header and body is not a box that Open accepts.
+	forged := append(append(mac[:], header...), body...)
+	if _, ok := Open(nil, forged, nonce, key); ok {
+		t.Error("Open accepted a MACed header and body as a box")
+	}
+
+	badHeader := []byte{0x02, 0x00, 0x00, 0x05}
+	if h.Verify(mac, badHeader, body, key, nonce) {
+		t.Error("verified MAC with modified header")
+	}
+	if h.Verify(mac, header, []byte("hellO"), key, nonce) {
+		t.Error("verified MAC with modified body")
+	}
+	if h.Verify(mac, header, body, key, nacl.NewNonce()) {
+		t.Error("verified MAC with wrong nonce")
+	}
+	if h.Verify(mac, header, body, nacl.NewKey(), nonce) {
+		t.Error("verified MAC with wrong key")
+	}
+	// Moving a byte between the header and body must be detected.
+	if h.Verify(mac, header[:3], append(header[3:4:4], body...), key, nonce) {
+		t.Error("verified MAC with short header")
+	}
+}
+
+func TestHeaderMACSizePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for incorrect header size")
+		}
+	}()
+	NewAuthenticatedHeader(4).MAC(make([]byte, 3), nil, nacl.NewKey(), nacl.NewNonce())
+}
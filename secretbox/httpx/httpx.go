@@ -0,0 +1,52 @@
+// Package httpx serves encrypted streams over HTTP.
+package httpx
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox/gcm"
+)
+
+// ServeSealed decrypts a stream in the secretbox/gcm format read from r with
+// key, and writes the plaintext to w, flushing after each chunk if w
+// implements http.Flusher so clients see progress.
+//
+// Nothing is written until the first chunk has been authenticated. If it
+// cannot be, ServeSealed replies with a 500 Internal Server Error and returns
+// the error. An error in a later chunk is returned after earlier chunks have
+// been sent; the response status can no longer be changed, so callers should
+// abort the response, for example by panicking with http.ErrAbortHandler, so
+// the client does not mistake a truncated body for a complete one.
+func ServeSealed(w http.ResponseWriter, r io.Reader, key nacl.Key) error {
+	dec := gcm.NewReader(r, key)
+	defer dec.Close()
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, gcm.ChunkSize)
+	started := false
+	for {
+		n, err := dec.Read(buf)
+		if n > 0 {
+			started = true
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			if !started {
+				w.WriteHeader(http.StatusOK)
+			}
+			return nil
+		}
+		if err != nil {
+			if !started {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return err
+		}
+	}
+}
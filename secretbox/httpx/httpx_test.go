@@ -0,0 +1,78 @@
+package httpx
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/secretbox/gcm"
+)
+
+func encrypt(t *testing.T, plaintext []byte, key nacl.Key) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gcm.NewWriter(&buf, key)
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestServeSealed(t *testing.T) {
+	key := nacl.NewKey()
+	for _, size := range []int{0, 100, 3*gcm.ChunkSize + 1} {
+		plaintext := make([]byte, size)
+		randombytes.MustRead(plaintext)
+		w := httptest.NewRecorder()
+		if err := ServeSealed(w, bytes.NewReader(encrypt(t, plaintext, key)), key); err != nil {
+			t.Fatalf("%d bytes: %v", size, err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("%d bytes: got status %d, want 200", size, w.Code)
+		}
+		if !bytes.Equal(w.Body.Bytes(), plaintext) {
+			t.Errorf("%d bytes: body does not match plaintext", size)
+		}
+		if size > 0 && !w.Flushed {
+			t.Errorf("%d bytes: response was not flushed", size)
+		}
+	}
+}
+
+func TestServeSealedFirstChunkInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := []byte("top secret document")
+	w := httptest.NewRecorder()
+	if err := ServeSealed(w, bytes.NewReader(encrypt(t, plaintext, key)), nacl.NewKey()); err == nil {
+		t.Fatal("expected error with wrong key")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want 500", w.Code)
+	}
+	if bytes.Contains(w.Body.Bytes(), plaintext) || w.Flushed {
+		t.Error("wrote plaintext before authentication")
+	}
+}
+
+func TestServeSealedLaterChunkInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	plaintext := make([]byte, 2*gcm.ChunkSize+1)
+	ciphertext := encrypt(t, plaintext, key)
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	w := httptest.NewRecorder()
+	if err := ServeSealed(w, bytes.NewReader(ciphertext), key); err == nil {
+		t.Fatal("expected error for modified final chunk")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want 200", w.Code)
+	}
+	if w.Body.Len() != 2*gcm.ChunkSize {
+		t.Errorf("got %d bytes, want the %d bytes of valid chunks", w.Body.Len(), 2*gcm.ChunkSize)
+	}
+}
@@ -0,0 +1,44 @@
+package secretbox
+
+import (
+	"errors"
+
+	"github.com/kevinburke/nacl"
+)
+
+var errRecordTooLarge = errors.New("secretbox: record larger than 65535 bytes")
+
+// SealPacked seals records together in a single box, so a batch of small
+// records costs one nonce and one tag rather than one of each per record.
+// Each record is stored in the plaintext with a two byte big-endian length
+// prefix, the same format used by BurstSealer, and a random nonce is
+// generated and prepended to the output, as with EasySeal. The output is
+// 24+Overhead+2*len(records) bytes longer than the total length of the
+// records.
+//
+// SealPacked returns an error if any record is longer than 65535 bytes.
+func SealPacked(records [][]byte, key nacl.Key) ([]byte, error) {
+	n := 0
+	for _, r := range records {
+		if len(r) > 0xffff {
+			return nil, errRecordTooLarge
+		}
+		n += 2 + len(r)
+	}
+	plaintext := make([]byte, 0, n)
+	for _, r := range records {
+		plaintext = append(plaintext, byte(len(r)>>8), byte(len(r)))
+		plaintext = append(plaintext, r...)
+	}
+	return EasySeal(plaintext, key), nil
+}
+
+// OpenPacked authenticates and decrypts a box produced by SealPacked, and
+// returns the records in order.
+func OpenPacked(box []byte, key nacl.Key) ([][]byte, error) {
+	records, ok := OpenBurst(box, key)
+	if !ok {
+		return nil, errInvalidInput
+	}
+	return records, nil
+}
@@ -0,0 +1,85 @@
+package secretbox
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSealPacked(t *testing.T) {
+	key := nacl.NewKey()
+	records := make([][]byte, 1000)
+	total := 0
+	for i := range records {
+		records[i] = []byte(fmt.Sprintf("r%d", i))
+		total += len(records[i])
+	}
+	records[10] = []byte{}
+	total -= len("r10")
+
+	box, err := SealPacked(records, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One nonce and one tag for the whole batch, plus the length prefixes.
+	if want := total + 2*len(records) + 24 + Overhead; len(box) != want {
+		t.Errorf("got %d byte box, want %d", len(box), want)
+	}
+	opened, err := OpenPacked(box, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != len(records) {
+		t.Fatalf("got %d records, want %d", len(opened), len(records))
+	}
+	for i := range records {
+		if !bytes.Equal(opened[i], records[i]) {
+			t.Errorf("record %d: got %q, want %q", i, opened[i], records[i])
+		}
+	}
+
+	box[len(box)-1] ^= 1
+	if _, err := OpenPacked(box, key); err == nil {
+		t.Error("opened modified box")
+	}
+}
+
+func TestSealPackedEmpty(t *testing.T) {
+	key := nacl.NewKey()
+	box, err := SealPacked(nil, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := OpenPacked(box, key)
+	if err != nil || len(records) != 0 {
+		t.Errorf("got %q, %v, want no records", records, err)
+	}
+}
+
+func TestSealPackedTooLarge(t *testing.T) {
+	key := nacl.NewKey()
+	if _, err := SealPacked([][]byte{make([]byte, 0x10000)}, key); err != errRecordTooLarge {
+		t.Errorf("got error %v, want %v", err, errRecordTooLarge)
+	}
+	box, err := SealPacked([][]byte{make([]byte, 0xffff)}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, err := OpenPacked(box, key); err != nil || len(records[0]) != 0xffff {
+		t.Errorf("could not round trip a 65535 byte record: %v", err)
+	}
+}
+
+func BenchmarkSealPacked(b *testing.B) {
+	key := nacl.NewKey()
+	records := make([][]byte, 100)
+	for i := range records {
+		records[i] = make([]byte, 32)
+	}
+	b.SetBytes(100 * 32)
+	for i := 0; i < b.N; i++ {
+		SealPacked(records, key)
+	}
+}
@@ -0,0 +1,70 @@
+// Package padded seals messages with secretbox after padding them, so the
+// length of a box reveals less about the length of the message inside it.
+//
+// Messages are padded as in ISO/IEC 7816-4: a 0x80 byte is appended, followed
+// by as many zero bytes as are needed to reach the padded length.
+package padded
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// MinBucketSize is the smallest padded length used by SealBucketed, in bytes.
+// Every message shorter than MinBucketSize bytes produces a box of the same
+// size.
+const MinBucketSize = 256
+
+// BucketSize returns the padded length of a message of n bytes: the smallest
+// power of two greater than n, and at least MinBucketSize. The padding always
+// includes at least one byte.
+func BucketSize(n int) int {
+	size := MinBucketSize
+	for size <= n {
+		size <<= 1
+	}
+	return size
+}
+
+func pad(message []byte, size int) []byte {
+	padded := make([]byte, size)
+	copy(padded, message)
+	padded[len(message)] = 0x80
+	return padded
+}
+
+func unpad(padded []byte) ([]byte, bool) {
+	for i := len(padded) - 1; i >= 0; i-- {
+		switch padded[i] {
+		case 0:
+		case 0x80:
+			return padded[:i], true
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// SealBucketed pads message to BucketSize(len(message)) bytes and seals it
+// with secretbox.Seal. The box is secretbox.Overhead bytes longer than the
+// padded message, so messages whose lengths fall in the same bucket produce
+// boxes of the same length. Padding to powers of two costs at most twice the
+// length of the message; it hides the exact length, but not its order of
+// magnitude.
+//
+// As with secretbox.Seal, the key and nonce pair must be unique for each
+// distinct message.
+func SealBucketed(message []byte, nonce nacl.Nonce, key nacl.Key) []byte {
+	return secretbox.Seal(nil, pad(message, BucketSize(len(message))), nonce, key)
+}
+
+// OpenBucketed authenticates and decrypts a box produced by SealBucketed and
+// returns the message with its padding removed.
+func OpenBucketed(box []byte, nonce nacl.Nonce, key nacl.Key) ([]byte, bool) {
+	padded, ok := secretbox.Open(nil, box, nonce, key)
+	if !ok {
+		return nil, false
+	}
+	return unpad(padded)
+}
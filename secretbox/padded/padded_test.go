@@ -0,0 +1,72 @@
+package padded
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+var bucketTests = []struct {
+	n    int
+	size int
+}{
+	{0, 256},
+	{1, 256},
+	{255, 256},
+	{256, 512},
+	{257, 512},
+	{511, 512},
+	{512, 1024},
+	{1000, 1024},
+	{1023, 1024},
+	{1024, 2048},
+	{1 << 20, 1 << 21},
+}
+
+func TestBucketSize(t *testing.T) {
+	for _, tt := range bucketTests {
+		if got := BucketSize(tt.n); got != tt.size {
+			t.Errorf("BucketSize(%d): got %d, want %d", tt.n, got, tt.size)
+		}
+	}
+}
+
+func TestSealBucketed(t *testing.T) {
+	key := nacl.NewKey()
+	for _, tt := range bucketTests {
+		nonce := nacl.NewNonce()
+		message := bytes.Repeat([]byte{0x80}, tt.n)
+		box := SealBucketed(message, nonce, key)
+		if len(box) != tt.size+secretbox.Overhead {
+			t.Errorf("%d byte message: got %d byte box, want %d", tt.n, len(box), tt.size+secretbox.Overhead)
+		}
+		opened, ok := OpenBucketed(box, nonce, key)
+		if !ok {
+			t.Fatalf("%d byte message: could not open box", tt.n)
+		}
+		if !bytes.Equal(opened, message) {
+			t.Errorf("%d byte message: got %d bytes back", tt.n, len(opened))
+		}
+		box[len(box)-1] ^= 1
+		if _, ok := OpenBucketed(box, nonce, key); ok {
+			t.Errorf("%d byte message: opened modified box", tt.n)
+		}
+	}
+}
+
+func TestOpenBucketedInvalidPadding(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	for _, padded := range [][]byte{
+		{},
+		make([]byte, 256),
+		append(make([]byte, 255), 1),
+	} {
+		box := secretbox.Seal(nil, padded, nonce, key)
+		if _, ok := OpenBucketed(box, nonce, key); ok {
+			t.Errorf("opened box with invalid padding %x", padded)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package padded
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/randombytes"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+var errPadRange = errors.New("padded: invalid padding range")
+
+// randomInt returns a uniformly random integer in [0, n).
+func randomInt(n uint64) (uint64, error) {
+	// Reject values from the final, partial copy of [0, n) in the uint64
+	// range, so that every result is equally likely.
+	limit := ^uint64(0) - ^uint64(0)%n
+	var buf [8]byte
+	for {
+		if _, err := randombytes.Read(buf[:]); err != nil {
+			return 0, err
+		}
+		if v := binary.BigEndian.Uint64(buf[:]); v < limit {
+			return v % n, nil
+		}
+	}
+}
+
+// SealRandom pads message with between minPad and maxPad bytes, inclusive,
+// the number chosen uniformly at random, and seals it with secretbox.Seal.
+// The padding includes the 0x80 byte that marks where it starts, so minPad
+// must be at least 1; SealRandom returns an error if it is not, or if maxPad
+// is less than minPad.
+//
+// Random padding costs, on average, (minPad+maxPad)/2 bytes per message, less
+// than bucketing for large messages, but it only blurs the length of a
+// message: an observer who sees many boxes of the same message can still
+// estimate its length. Use SealBucketed where that matters.
+//
+// As with secretbox.Seal, the key and nonce pair must be unique for each
+// distinct message.
+func SealRandom(message []byte, minPad, maxPad int, nonce nacl.Nonce, key nacl.Key) ([]byte, error) {
+	if minPad < 1 || maxPad < minPad {
+		return nil, errPadRange
+	}
+	extra, err := randomInt(uint64(maxPad-minPad) + 1)
+	if err != nil {
+		return nil, err
+	}
+	return secretbox.Seal(nil, pad(message, len(message)+minPad+int(extra)), nonce, key), nil
+}
+
+// OpenRandom authenticates and decrypts a box produced by SealRandom and
+// returns the message with its padding removed.
+func OpenRandom(box []byte, nonce nacl.Nonce, key nacl.Key) ([]byte, bool) {
+	return OpenBucketed(box, nonce, key)
+}
@@ -0,0 +1,68 @@
+package padded
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+func TestSealRandom(t *testing.T) {
+	key := nacl.NewKey()
+	message := []byte("attack at dawn")
+	const minPad, maxPad = 1, 16
+	lengths := make(map[int]bool)
+	for i := 0; i < 200; i++ {
+		nonce := nacl.NewNonce()
+		box, err := SealRandom(message, minPad, maxPad, nonce, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		padLen := len(box) - secretbox.Overhead - len(message)
+		if padLen < minPad || padLen > maxPad {
+			t.Fatalf("got %d bytes of padding, want between %d and %d", padLen, minPad, maxPad)
+		}
+		lengths[len(box)] = true
+		opened, ok := OpenRandom(box, nonce, key)
+		if !ok {
+			t.Fatal("could not open box")
+		}
+		if !bytes.Equal(opened, message) {
+			t.Fatalf("got %q, want %q", opened, message)
+		}
+	}
+	// With 200 boxes and 16 possible lengths, seeing fewer than 8 distinct
+	// lengths is vanishingly unlikely unless the padding is not random.
+	if len(lengths) < 8 {
+		t.Errorf("got only %d distinct box lengths", len(lengths))
+	}
+}
+
+func TestSealRandomFixedPadding(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	for _, message := range [][]byte{nil, {0x80}, bytes.Repeat([]byte{0}, 100)} {
+		box, err := SealRandom(message, 5, 5, nonce, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := len(message) + 5 + secretbox.Overhead; len(box) != want {
+			t.Errorf("got %d byte box, want %d", len(box), want)
+		}
+		opened, ok := OpenRandom(box, nonce, key)
+		if !ok || !bytes.Equal(opened, message) {
+			t.Errorf("got %x, %t, want %x", opened, ok, message)
+		}
+	}
+}
+
+func TestSealRandomInvalidRange(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	for _, r := range [][2]int{{0, 10}, {-1, 10}, {10, 9}} {
+		if _, err := SealRandom([]byte("message"), r[0], r[1], nonce, key); err != errPadRange {
+			t.Errorf("range %v: got error %v, want %v", r, err, errPadRange)
+		}
+	}
+}
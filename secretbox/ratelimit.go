@@ -0,0 +1,95 @@
+package secretbox
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+// ErrRateLimited is returned by DecryptRateLimiter.Open when no tokens are
+// available.
+var ErrRateLimited = errors.New("secretbox: decryption rate limit exceeded")
+
+// A DecryptRateLimiter bounds the rate at which boxes are opened, for example
+// to limit the CPU an attacker can consume by submitting invalid ciphertexts
+// to a network service. It is a token bucket: each call to Open consumes a
+// token, and tokens are replenished at a fixed rate up to a maximum.
+//
+// A DecryptRateLimiter is safe for concurrent use by multiple goroutines.
+type DecryptRateLimiter struct {
+	tokens   int64 // accessed atomically
+	burst    int64
+	interval time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+// NewDecryptRateLimiter returns a DecryptRateLimiter that allows rate calls to
+// Open per second, and bursts of up to burst calls. The bucket starts full.
+// Call Stop to release the timer used to replenish tokens. NewDecryptRateLimiter
+// panics if rate or burst is not positive.
+func NewDecryptRateLimiter(rate int, burst int) *DecryptRateLimiter {
+	if rate <= 0 || burst <= 0 {
+		panic("secretbox: rate and burst must be positive")
+	}
+	r := &DecryptRateLimiter{
+		tokens:   int64(burst),
+		burst:    int64(burst),
+		interval: time.Second / time.Duration(rate),
+	}
+	r.timer = time.AfterFunc(r.interval, r.replenish)
+	return r
+}
+
+func (r *DecryptRateLimiter) replenish() {
+	for {
+		n := atomic.LoadInt64(&r.tokens)
+		if n >= r.burst || atomic.CompareAndSwapInt64(&r.tokens, n, n+1) {
+			break
+		}
+	}
+	r.mu.Lock()
+	if !r.stopped {
+		r.timer = time.AfterFunc(r.interval, r.replenish)
+	}
+	r.mu.Unlock()
+}
+
+func (r *DecryptRateLimiter) take() bool {
+	for {
+		n := atomic.LoadInt64(&r.tokens)
+		if n <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&r.tokens, n, n-1) {
+			return true
+		}
+	}
+}
+
+// Stop stops replenishing tokens. Calls to Open will succeed until the
+// remaining tokens are used up.
+func (r *DecryptRateLimiter) Stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.timer.Stop()
+	r.mu.Unlock()
+}
+
+// Open consumes a token and calls Open to authenticate and decrypt box. If no
+// tokens are available, box is not examined and ErrRateLimited is returned.
+func (r *DecryptRateLimiter) Open(out, box []byte, nonce nacl.Nonce, key nacl.Key) ([]byte, error) {
+	if !r.take() {
+		return nil, ErrRateLimited
+	}
+	opened, ok := Open(out, box, nonce, key)
+	if !ok {
+		return nil, errInvalidInput
+	}
+	return opened, nil
+}
@@ -0,0 +1,105 @@
+package secretbox
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestDecryptRateLimiter(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	message := []byte("hello world")
+	box := Seal(nil, message, nonce, key)
+
+	r := NewDecryptRateLimiter(1, 3)
+	defer r.Stop()
+	for i := 0; i < 3; i++ {
+		opened, err := r.Open(nil, box, nonce, key)
+		if err != nil {
+			t.Fatalf("open %d: %v", i, err)
+		}
+		if !bytes.Equal(opened, message) {
+			t.Fatalf("open %d: got %q, want %q", i, opened, message)
+		}
+	}
+	if _, err := r.Open(nil, box, nonce, key); err != ErrRateLimited {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestDecryptRateLimiterInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	box := Seal(nil, []byte("hello world"), nonce, key)
+	box[0] ^= 0x01
+
+	r := NewDecryptRateLimiter(1, 1)
+	defer r.Stop()
+	if _, err := r.Open(nil, box, nonce, key); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+	if _, err := r.Open(nil, box, nonce, key); err != ErrRateLimited {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestDecryptRateLimiterReplenish(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	box := Seal(nil, []byte("hello world"), nonce, key)
+
+	r := NewDecryptRateLimiter(100, 1)
+	defer r.Stop()
+	if _, err := r.Open(nil, box, nonce, key); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := r.Open(nil, box, nonce, key)
+		if err == nil {
+			break
+		}
+		if err != ErrRateLimited {
+			t.Fatal(err)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("tokens were not replenished")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDecryptRateLimiterConcurrent(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	box := Seal(nil, []byte("hello world"), nonce, key)
+
+	const burst = 50
+	r := NewDecryptRateLimiter(1, burst)
+	defer r.Stop()
+	var mu sync.Mutex
+	succeeded := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := r.Open(nil, box, nonce, key); err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// Allow for a token or two replenished while the test was running.
+	if succeeded < burst || succeeded > burst+2 {
+		t.Errorf("got %d successful opens, want about %d", succeeded, burst)
+	}
+}
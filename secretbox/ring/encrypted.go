@@ -0,0 +1,95 @@
+package ring
+
+import (
+	"sync"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+type ringEntry struct {
+	nonce [24]byte
+	box   []byte
+}
+
+// An EncryptedRing holds the most recent messages pushed to it, up to a fixed
+// capacity, sealed with secretbox, so that a copy of the ring's memory - in a
+// heap dump or swap, say - does not show them in plaintext. The key is kept
+// in the same process, so this only helps against leaks of the ring's memory,
+// not of the whole process.
+//
+// Each message is sealed with the next nonce in a sequence that starts at a
+// random nonce and is incremented, as a big-endian integer, after each
+// message, and the nonce is stored next to the box.
+//
+// An EncryptedRing is safe for concurrent use.
+type EncryptedRing struct {
+	mu      sync.Mutex
+	key     [32]byte
+	nonce   [24]byte
+	entries []ringEntry
+	start   int // index of the oldest entry
+	n       int // number of entries
+}
+
+// NewEncryptedRing returns an empty EncryptedRing that holds up to capacity
+// messages, sealed with key. NewEncryptedRing panics if capacity is not
+// positive.
+func NewEncryptedRing(capacity int, key nacl.Key) *EncryptedRing {
+	if capacity <= 0 {
+		panic("ring: invalid ring capacity")
+	}
+	return &EncryptedRing{
+		key:     *key,
+		nonce:   *nacl.NewNonce(),
+		entries: make([]ringEntry, capacity),
+	}
+}
+
+// Len returns the number of messages in the ring.
+func (r *EncryptedRing) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.n
+}
+
+// Push seals message and adds it to the ring. If the ring is full, the oldest
+// message is discarded to make room.
+func (r *EncryptedRing) Push(message []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := (r.start + r.n) % len(r.entries)
+	if r.n == len(r.entries) {
+		r.start = (r.start + 1) % len(r.entries)
+	} else {
+		r.n++
+	}
+	e := &r.entries[i]
+	e.nonce = r.nonce
+	e.box = secretbox.Seal(e.box[:0], message, &e.nonce, &r.key)
+	for j := len(r.nonce) - 1; j >= 0; j-- {
+		r.nonce[j]++
+		if r.nonce[j] != 0 {
+			break
+		}
+	}
+}
+
+// Pop removes the oldest message from the ring and returns it, decrypted. If
+// the ring is empty, Pop returns nil and false. Pop panics if the oldest
+// entry fails to authenticate, which means the ring's memory was modified.
+func (r *EncryptedRing) Pop() ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.n == 0 {
+		return nil, false
+	}
+	e := &r.entries[r.start]
+	message, ok := secretbox.Open(nil, e.box, &e.nonce, &r.key)
+	if !ok {
+		panic("ring: could not open entry; ring memory is corrupted")
+	}
+	r.start = (r.start + 1) % len(r.entries)
+	r.n--
+	return message, true
+}
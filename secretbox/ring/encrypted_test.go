@@ -0,0 +1,79 @@
+package ring
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestEncryptedRing(t *testing.T) {
+	r := NewEncryptedRing(3, nacl.NewKey())
+	if _, ok := r.Pop(); ok {
+		t.Fatal("popped from an empty ring")
+	}
+	for i := 0; i < 5; i++ {
+		r.Push([]byte(fmt.Sprintf("message %d", i)))
+	}
+	if r.Len() != 3 {
+		t.Fatalf("got length %d, want 3", r.Len())
+	}
+	for _, e := range r.entries {
+		if bytes.Contains(e.box, []byte("message")) {
+			t.Errorf("entry stored in plaintext: %q", e.box)
+		}
+	}
+	if r.entries[0].nonce == r.entries[1].nonce || r.entries[1].nonce == r.entries[2].nonce {
+		t.Error("entries share a nonce")
+	}
+	// The two oldest messages were discarded.
+	for i := 2; i < 5; i++ {
+		got, ok := r.Pop()
+		if !ok {
+			t.Fatalf("could not pop message %d", i)
+		}
+		if want := fmt.Sprintf("message %d", i); string(got) != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if _, ok := r.Pop(); ok || r.Len() != 0 {
+		t.Error("ring not empty after popping every message")
+	}
+
+	r.Push([]byte("after"))
+	if got, ok := r.Pop(); !ok || string(got) != "after" {
+		t.Errorf("got %q, %t, want %q", got, ok, "after")
+	}
+}
+
+func TestEncryptedRingCopiesKey(t *testing.T) {
+	key := nacl.NewKey()
+	r := NewEncryptedRing(1, key)
+	r.Push([]byte("hello"))
+	key[0] ^= 1
+	if got, ok := r.Pop(); !ok || string(got) != "hello" {
+		t.Errorf("got %q, %t after changing caller's key", got, ok)
+	}
+}
+
+func TestNewEncryptedRingPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewEncryptedRing(0) did not panic")
+		}
+	}()
+	NewEncryptedRing(0, nacl.NewKey())
+}
+
+func TestEncryptedRingPopPanics(t *testing.T) {
+	r := NewEncryptedRing(2, nacl.NewKey())
+	r.Push([]byte("hello"))
+	r.entries[0].box[0] ^= 1
+	defer func() {
+		if recover() == nil {
+			t.Error("Pop of a corrupted entry did not panic")
+		}
+	}()
+	r.Pop()
+}
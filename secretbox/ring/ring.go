@@ -0,0 +1,98 @@
+// Package ring seals messages directly into the slots of a fixed size ring
+// buffer, for passing encrypted messages between a producer and a consumer
+// goroutine without allocating.
+package ring
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+var (
+	// ErrFull is returned by SealRing when every slot holds a box that has not
+	// been read yet.
+	ErrFull = errors.New("ring: buffer is full")
+
+	errTooLarge = errors.New("ring: message is larger than the slot size")
+)
+
+// A RingBuffer is a fixed number of slots, each large enough to hold a box
+// containing a message of up to the maximum message size. Boxes are written
+// with SealRing and read back, in order, with Read.
+//
+// A RingBuffer is safe for use by one producer goroutine calling SealRing and
+// one consumer goroutine calling Read at the same time. It is not safe for use
+// by multiple producers or multiple consumers.
+type RingBuffer struct {
+	buf      []byte
+	lengths  []int
+	slotSize int
+
+	head uint64 // next slot to write; accessed atomically
+	tail uint64 // next slot to read; accessed atomically
+}
+
+// NewRingBuffer returns a RingBuffer with the given number of slots, each
+// large enough to hold a box containing a message of up to maxMessageSize
+// bytes. NewRingBuffer panics if slots is not positive or maxMessageSize is
+// negative.
+func NewRingBuffer(slots, maxMessageSize int) *RingBuffer {
+	if slots <= 0 || maxMessageSize < 0 {
+		panic("ring: invalid ring buffer size")
+	}
+	slotSize := maxMessageSize + secretbox.Overhead
+	return &RingBuffer{
+		buf:      make([]byte, slots*slotSize),
+		lengths:  make([]int, slots),
+		slotSize: slotSize,
+	}
+}
+
+// Bytes returns the memory backing the ring buffer. The box written by a call
+// to SealRing starts at the returned offset.
+func (r *RingBuffer) Bytes() []byte {
+	return r.buf
+}
+
+// Len returns the number of boxes that have been written but not yet read.
+func (r *RingBuffer) Len() int {
+	return int(atomic.LoadUint64(&r.head) - atomic.LoadUint64(&r.tail))
+}
+
+// Read copies the oldest unread box to out, frees its slot, and returns the
+// resulting slice. If there are no unread boxes, Read returns nil and false.
+func (r *RingBuffer) Read(out []byte) ([]byte, bool) {
+	tail := atomic.LoadUint64(&r.tail)
+	if tail == atomic.LoadUint64(&r.head) {
+		return nil, false
+	}
+	slot := int(tail % uint64(len(r.lengths)))
+	off := slot * r.slotSize
+	out = append(out, r.buf[off:off+r.lengths[slot]]...)
+	atomic.StoreUint64(&r.tail, tail+1)
+	return out, true
+}
+
+// SealRing seals message with secretbox.Seal directly into the next free slot
+// of ring, and returns the offset of the box in ring.Bytes(). The box is
+// len(message)+secretbox.Overhead bytes long. SealRing does not allocate.
+//
+// If every slot holds a box that has not been read, SealRing returns ErrFull.
+func SealRing(ring *RingBuffer, message []byte, nonce nacl.Nonce, key nacl.Key) (offset int, err error) {
+	if len(message)+secretbox.Overhead > ring.slotSize {
+		return 0, errTooLarge
+	}
+	head := atomic.LoadUint64(&ring.head)
+	if head-atomic.LoadUint64(&ring.tail) == uint64(len(ring.lengths)) {
+		return 0, ErrFull
+	}
+	slot := int(head % uint64(len(ring.lengths)))
+	offset = slot * ring.slotSize
+	box := secretbox.Seal(ring.buf[offset:offset:offset+ring.slotSize], message, nonce, key)
+	ring.lengths[slot] = len(box)
+	atomic.StoreUint64(&ring.head, head+1)
+	return offset, nil
+}
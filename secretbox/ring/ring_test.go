@@ -0,0 +1,146 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"runtime"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+func TestSealRing(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	r := NewRingBuffer(3, 16)
+	for i := 0; i < 3; i++ {
+		message := []byte{byte(i), byte(i)}
+		offset, err := SealRing(r, message, nonce, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i * (16 + secretbox.Overhead); offset != want {
+			t.Errorf("slot %d: got offset %d, want %d", i, offset, want)
+		}
+		box := r.Bytes()[offset : offset+len(message)+secretbox.Overhead]
+		if opened, ok := secretbox.Open(nil, box, nonce, key); !ok || !bytes.Equal(opened, message) {
+			t.Errorf("slot %d: could not open box", i)
+		}
+	}
+	if _, err := SealRing(r, nil, nonce, key); err != ErrFull {
+		t.Fatalf("expected ErrFull, got %v", err)
+	}
+
+	// Free a slot and check that the next write wraps around to it.
+	if _, ok := r.Read(nil); !ok {
+		t.Fatal("could not read from full ring")
+	}
+	offset, err := SealRing(r, []byte("wrapped"), nonce, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 0 {
+		t.Errorf("got offset %d after wraparound, want 0", offset)
+	}
+	for i := 1; i < 3; i++ {
+		box, ok := r.Read(nil)
+		if !ok {
+			t.Fatalf("could not read slot %d", i)
+		}
+		if opened, ok := secretbox.Open(nil, box, nonce, key); !ok || !bytes.Equal(opened, []byte{byte(i), byte(i)}) {
+			t.Errorf("slot %d: could not open box", i)
+		}
+	}
+	box, ok := r.Read(nil)
+	if !ok {
+		t.Fatal("could not read wrapped slot")
+	}
+	if opened, ok := secretbox.Open(nil, box, nonce, key); !ok || string(opened) != "wrapped" {
+		t.Errorf("could not open wrapped box")
+	}
+	if _, ok := r.Read(nil); ok {
+		t.Error("read from empty ring")
+	}
+}
+
+func TestSealRingTooLarge(t *testing.T) {
+	r := NewRingBuffer(1, 16)
+	if _, err := SealRing(r, make([]byte, 17), nacl.NewNonce(), nacl.NewKey()); err != errTooLarge {
+		t.Errorf("expected too large error, got %v", err)
+	}
+	if r.Len() != 0 {
+		t.Errorf("got length %d, want 0", r.Len())
+	}
+}
+
+func TestSealRingAllocs(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	message := make([]byte, 64)
+	r := NewRingBuffer(1, len(message))
+	out := make([]byte, 0, len(message)+secretbox.Overhead)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := SealRing(r, message, nonce, key); err != nil {
+			t.Fatal(err)
+		}
+		r.Read(out[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations per call, want 0", allocs)
+	}
+}
+
+func TestProducerConsumer(t *testing.T) {
+	const messages = 10000
+	key := nacl.NewKey()
+	r := NewRingBuffer(8, 8)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nonce := new([24]byte)
+		message := make([]byte, 8)
+		for i := uint64(0); i < messages; {
+			binary.BigEndian.PutUint64(message, i)
+			binary.BigEndian.PutUint64(nonce[:], i)
+			_, err := SealRing(r, message, nonce, key)
+			if err == ErrFull {
+				runtime.Gosched()
+				continue
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			i++
+		}
+	}()
+
+	nonce := new([24]byte)
+	var box []byte
+	for i := uint64(0); i < messages; {
+		var ok bool
+		box, ok = r.Read(box[:0])
+		if !ok {
+			select {
+			case <-done:
+				if r.Len() == 0 {
+					t.Fatalf("producer stopped after %d messages", i)
+				}
+			default:
+			}
+			runtime.Gosched()
+			continue
+		}
+		binary.BigEndian.PutUint64(nonce[:], i)
+		opened, ok := secretbox.Open(nil, box, nonce, key)
+		if !ok {
+			t.Fatalf("message %d: could not open box", i)
+		}
+		if got := binary.BigEndian.Uint64(opened); got != i {
+			t.Fatalf("got message %d, want %d", got, i)
+		}
+		i++
+	}
+	<-done
+}
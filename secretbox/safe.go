@@ -0,0 +1,21 @@
+package secretbox
+
+import (
+	"errors"
+
+	"github.com/kevinburke/nacl"
+)
+
+// ErrNonceReused is returned by SafeSeal when a nonce has already been used.
+var ErrNonceReused = errors.New("secretbox: nonce reused")
+
+// SafeSeal is like Seal, but first checks nonce against ring, which must only
+// be used with key. If ring has seen nonce recently, nothing is sealed and
+// SafeSeal returns ErrNonceReused; otherwise the nonce is recorded in ring.
+// See nacl.NonceRing for the limits of this check.
+func SafeSeal(out, message []byte, nonce nacl.Nonce, key nacl.Key, ring *nacl.NonceRing) ([]byte, error) {
+	if !ring.Use(nonce) {
+		return nil, ErrNonceReused
+	}
+	return Seal(out, message, nonce, key), nil
+}
@@ -0,0 +1,28 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSafeSeal(t *testing.T) {
+	key := nacl.NewKey()
+	ring := nacl.NewNonceRing(16)
+	nonce := nacl.NewNonce()
+	message := []byte("hello")
+	box, err := SafeSeal(nil, message, nonce, key, ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(box, Seal(nil, message, nonce, key)) {
+		t.Error("SafeSeal output differs from Seal")
+	}
+	if _, err := SafeSeal(nil, []byte("goodbye"), nonce, key, ring); err != ErrNonceReused {
+		t.Errorf("got error %v, want %v", err, ErrNonceReused)
+	}
+	if _, err := SafeSeal(nil, message, nacl.NewNonce(), key, ring); err != nil {
+		t.Errorf("fresh nonce: %v", err)
+	}
+}
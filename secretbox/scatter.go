@@ -0,0 +1,67 @@
+package secretbox
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/bufutil"
+	"github.com/kevinburke/nacl/onetimeauth"
+)
+
+// SealScatterGather appends an encrypted and authenticated copy of the
+// concatenation of parts to out, which must not overlap any of the parts. The
+// result is identical to sealing the concatenated parts with Seal: the
+// ciphertext has no separators and the tag covers all of the parts, so they
+// can only be opened together. The key and nonce pair must be unique for
+// each distinct message and the output will be Overhead bytes longer than the
+// combined length of the parts.
+func SealScatterGather(out []byte, parts [][]byte, nonce nacl.Nonce, key nacl.Key) []byte {
+	var subKey, poly1305Key [32]byte
+	var counter [16]byte
+	var firstBlock [64]byte
+	setupKeyStream(&subKey, &poly1305Key, &counter, &firstBlock, nonce, key)
+
+	n := 0
+	for _, part := range parts {
+		n += len(part)
+	}
+	ret, out := bufutil.AppendBuffer(out, n+onetimeauth.Size)
+	tagOut := out
+	ciphertext := out[onetimeauth.Size:]
+	// Gather the parts into the output and encrypt them in place.
+	off := 0
+	for _, part := range parts {
+		off += copy(ciphertext[off:], part)
+	}
+	xorKeyStream(ciphertext, ciphertext, &firstBlock, &counter, &subKey)
+
+	tag := onetimeauth.Sum(ciphertext, &poly1305Key)
+	copy(tagOut, tag[:])
+
+	return ret
+}
+
+// OpenScatterGather authenticates and decrypts a box produced by
+// SealScatterGather, and splits the message into parts with the given sizes.
+// It returns false if the box cannot be opened, or if the sizes do not add up
+// to the length of the message. The parts share a single underlying array.
+func OpenScatterGather(box []byte, sizes []int, nonce nacl.Nonce, key nacl.Key) ([][]byte, bool) {
+	n := 0
+	for _, size := range sizes {
+		if size < 0 {
+			return nil, false
+		}
+		n += size
+	}
+	if n != len(box)-Overhead {
+		return nil, false
+	}
+	message, ok := Open(nil, box, nonce, key)
+	if !ok {
+		return nil, false
+	}
+	parts := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		parts[i] = message[:size:size]
+		message = message[size:]
+	}
+	return parts, true
+}
@@ -0,0 +1,64 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestScatterGather(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	parts := [][]byte{
+		[]byte("alice"),
+		{},
+		bytes.Repeat([]byte{0xab}, 100),
+		[]byte("alice@example.com"),
+	}
+	sizes := make([]int, len(parts))
+	for i, part := range parts {
+		sizes[i] = len(part)
+	}
+
+	box := SealScatterGather(nil, parts, nonce, key)
+	if want := Seal(nil, bytes.Join(parts, nil), nonce, key); !bytes.Equal(box, want) {
+		t.Fatal("output differs from Seal of the concatenated parts")
+	}
+	opened, ok := OpenScatterGather(box, sizes, nonce, key)
+	if !ok {
+		t.Fatal("could not open box")
+	}
+	if len(opened) != len(parts) {
+		t.Fatalf("got %d parts, want %d", len(opened), len(parts))
+	}
+	for i := range parts {
+		if !bytes.Equal(opened[i], parts[i]) {
+			t.Errorf("part %d: got %q, want %q", i, opened[i], parts[i])
+		}
+	}
+
+	if _, ok := OpenScatterGather(box, []int{5, 0, 100, 16}, nonce, key); ok {
+		t.Error("opened box with sizes that do not add up")
+	}
+	if _, ok := OpenScatterGather(box, []int{6, -1, 100, 17}, nonce, key); ok {
+		t.Error("opened box with negative size")
+	}
+	box[len(box)-1] ^= 0x01
+	if _, ok := OpenScatterGather(box, sizes, nonce, key); ok {
+		t.Error("opened modified box")
+	}
+}
+
+func TestScatterGatherEmpty(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	box := SealScatterGather([]byte("prefix"), nil, nonce, key)
+	if len(box) != len("prefix")+Overhead {
+		t.Fatalf("got length %d, want %d", len(box), len("prefix")+Overhead)
+	}
+	parts, ok := OpenScatterGather(box[len("prefix"):], nil, nonce, key)
+	if !ok || len(parts) != 0 {
+		t.Errorf("could not open empty box")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 
 	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/bufutil"
 	"github.com/kevinburke/nacl/onetimeauth"
 	"golang.org/x/crypto/salsa20/salsa"
 )
@@ -28,6 +29,17 @@ import (
 // Overhead is the number of bytes of overhead when boxing a message.
 const Overhead = onetimeauth.Size
 
+// SizeBounds returns the smallest and largest sizes of the boxes produced by
+// Seal for messages between minPlaintext and maxPlaintext bytes long. Boxes
+// produced by EasySeal are a further 24 bytes longer. SizeBounds panics if
+// minPlaintext is negative or greater than maxPlaintext.
+func SizeBounds(minPlaintext, maxPlaintext int) (minCipher, maxCipher int) {
+	if minPlaintext < 0 || minPlaintext > maxPlaintext {
+		panic("secretbox: invalid plaintext size range")
+	}
+	return minPlaintext + Overhead, maxPlaintext + Overhead
+}
+
 // setup produces a sub-key and Salsa20 counter given a nonce and key.
 func setup(subKey nacl.Key, counter *[16]byte, nonce nacl.Nonce, key nacl.Key) {
 	// We use XSalsa20 for encryption so first we need to generate a
@@ -40,19 +52,35 @@ func setup(subKey nacl.Key, counter *[16]byte, nonce nacl.Nonce, key nacl.Key) {
 	copy(counter[:], nonce[16:])
 }
 
-// sliceForAppend takes a slice and a requested number of bytes. It returns a
-// slice with the contents of the given slice followed by that many bytes and a
-// second slice that aliases into it and contains only the extra bytes. If the
-// original slice has sufficient capacity then no allocation is performed.
-func sliceForAppend(in []byte, n int) (head, tail []byte) {
-	if total := len(in) + n; cap(in) >= total {
-		head = in[:total]
-	} else {
-		head = make([]byte, total)
-		copy(head, in)
+// setupKeyStream calls setup, generates the first block of keystream and
+// writes the Poly1305 key to poly1305Key. The Poly1305 key is generated by
+// encrypting 32 bytes of zeros. Since Salsa20 works with 64-byte blocks, we
+// also generate 32 bytes of keystream as a side effect, which xorKeyStream
+// uses for the start of the message.
+func setupKeyStream(subKey, poly1305Key nacl.Key, counter *[16]byte, firstBlock *[64]byte, nonce nacl.Nonce, key nacl.Key) {
+	setup(subKey, counter, nonce, key)
+	salsa.XORKeyStream(firstBlock[:], firstBlock[:], counter, subKey)
+	copy(poly1305Key[:], firstBlock[:])
+}
+
+// xorKeyStream encrypts or decrypts in to out, using the keystream state
+// produced by setupKeyStream.
+func xorKeyStream(out, in []byte, firstBlock *[64]byte, counter *[16]byte, subKey nacl.Key) {
+	// We XOR up to 32 bytes of in with the keystream generated from the
+	// first block.
+	firstMessageBlock := in
+	if len(firstMessageBlock) > 32 {
+		firstMessageBlock = firstMessageBlock[:32]
+	}
+	for i, x := range firstMessageBlock {
+		out[i] = firstBlock[32+i] ^ x
 	}
-	tail = head[len(in):]
-	return
+	in = in[len(firstMessageBlock):]
+	out = out[len(firstMessageBlock):]
+
+	// Now XOR the rest.
+	counter[8] = 1
+	salsa.XORKeyStream(out, in, counter, subKey)
 }
 
 // EasySeal encrypts message using key. A 24-byte nonce is generated and
@@ -68,40 +96,15 @@ func EasySeal(message []byte, key nacl.Key) []byte {
 // must not overlap message. The key and nonce pair must be unique for each
 // distinct message and the output will be Overhead bytes longer than message.
 func Seal(out, message []byte, nonce nacl.Nonce, key nacl.Key) []byte {
-	var subKey [32]byte
+	var subKey, poly1305Key [32]byte
 	var counter [16]byte
-	setup(&subKey, &counter, nonce, key)
-
-	// The Poly1305 key is generated by encrypting 32 bytes of zeros. Since
-	// Salsa20 works with 64-byte blocks, we also generate 32 bytes of
-	// keystream as a side effect.
 	var firstBlock [64]byte
-	salsa.XORKeyStream(firstBlock[:], firstBlock[:], &counter, &subKey)
-
-	var poly1305Key [32]byte
-	copy(poly1305Key[:], firstBlock[:])
-
-	ret, out := sliceForAppend(out, len(message)+onetimeauth.Size)
-
-	// We XOR up to 32 bytes of message with the keystream generated from
-	// the first block.
-	firstMessageBlock := message
-	if len(firstMessageBlock) > 32 {
-		firstMessageBlock = firstMessageBlock[:32]
-	}
+	setupKeyStream(&subKey, &poly1305Key, &counter, &firstBlock, nonce, key)
 
+	ret, out := bufutil.AppendBuffer(out, len(message)+onetimeauth.Size)
 	tagOut := out
-	out = out[onetimeauth.Size:]
-	for i, x := range firstMessageBlock {
-		out[i] = firstBlock[32+i] ^ x
-	}
-	message = message[len(firstMessageBlock):]
-	ciphertext := out
-	out = out[len(firstMessageBlock):]
-
-	// Now encrypt the rest.
-	counter[8] = 1
-	salsa.XORKeyStream(out, message, &counter, &subKey)
+	ciphertext := out[onetimeauth.Size:]
+	xorKeyStream(ciphertext, message, &firstBlock, &counter, &subKey)
 
 	tag := onetimeauth.Sum(ciphertext, &poly1305Key)
 	copy(tagOut, tag[:])
@@ -135,18 +138,11 @@ func Open(out []byte, box []byte, nonce nacl.Nonce, key nacl.Key) ([]byte, bool)
 		return nil, false
 	}
 
-	var subKey [32]byte
+	var subKey, poly1305Key [32]byte
 	var counter [16]byte
-	setup(&subKey, &counter, nonce, key)
-
-	// The Poly1305 key is generated by encrypting 32 bytes of zeros. Since
-	// Salsa20 works with 64-byte blocks, we also generate 32 bytes of
-	// keystream as a side effect.
 	var firstBlock [64]byte
-	salsa.XORKeyStream(firstBlock[:], firstBlock[:], &counter, &subKey)
+	setupKeyStream(&subKey, &poly1305Key, &counter, &firstBlock, nonce, key)
 
-	var poly1305Key [32]byte
-	copy(poly1305Key[:], firstBlock[:])
 	var tag [onetimeauth.Size]byte
 	copy(tag[:], box)
 
@@ -154,25 +150,8 @@ func Open(out []byte, box []byte, nonce nacl.Nonce, key nacl.Key) ([]byte, bool)
 		return nil, false
 	}
 
-	ret, out := sliceForAppend(out, len(box)-Overhead)
-
-	// We XOR up to 32 bytes of box with the keystream generated from
-	// the first block.
-	box = box[Overhead:]
-	firstMessageBlock := box
-	if len(firstMessageBlock) > 32 {
-		firstMessageBlock = firstMessageBlock[:32]
-	}
-	for i, x := range firstMessageBlock {
-		out[i] = firstBlock[32+i] ^ x
-	}
-
-	box = box[len(firstMessageBlock):]
-	out = out[len(firstMessageBlock):]
-
-	// Now decrypt the rest.
-	counter[8] = 1
-	salsa.XORKeyStream(out, box, &counter, &subKey)
+	ret, out := bufutil.AppendBuffer(out, len(box)-Overhead)
+	xorKeyStream(out, box[Overhead:], &firstBlock, &counter, &subKey)
 
 	return ret, true
 }
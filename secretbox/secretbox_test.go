@@ -89,6 +89,41 @@ func TestAppend(t *testing.T) {
 	}
 }
 
+var sizeBoundsTests = []struct {
+	minPlaintext, maxPlaintext int
+	minCipher, maxCipher       int
+}{
+	{0, 0, 16, 16},
+	{0, 1, 16, 17},
+	{5, 5, 21, 21},
+	{1, 1024, 17, 1040},
+	{0, 65536, 16, 65552},
+}
+
+func TestSizeBounds(t *testing.T) {
+	for _, tt := range sizeBoundsTests {
+		minCipher, maxCipher := SizeBounds(tt.minPlaintext, tt.maxPlaintext)
+		if minCipher != tt.minCipher || maxCipher != tt.maxCipher {
+			t.Errorf("SizeBounds(%d, %d): got (%d, %d), want (%d, %d)", tt.minPlaintext, tt.maxPlaintext, minCipher, maxCipher, tt.minCipher, tt.maxCipher)
+		}
+		var key [32]byte
+		var nonce [24]byte
+		if n := len(Seal(nil, make([]byte, tt.maxPlaintext), &nonce, &key)); n != maxCipher {
+			t.Errorf("Seal of %d bytes: got %d bytes, want %d", tt.maxPlaintext, n, maxCipher)
+		}
+	}
+	for _, bad := range [][2]int{{-1, 5}, {5, 4}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SizeBounds(%d, %d): expected panic", bad[0], bad[1])
+				}
+			}()
+			SizeBounds(bad[0], bad[1])
+		}()
+	}
+}
+
 func benchmarkSealSize(b *testing.B, size int) {
 	message := make([]byte, size)
 	out := make([]byte, size+Overhead)
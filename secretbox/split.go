@@ -0,0 +1,25 @@
+package secretbox
+
+import (
+	"github.com/kevinburke/nacl"
+)
+
+// OpenSplit authenticates and decrypts a message that was sealed as two
+// separate boxes, for example a header and a body kept in different places,
+// and returns both halves. It returns false if either box cannot be opened.
+//
+// The two boxes are not bound to each other: a header from one message and a
+// body from another, both sealed with key, open without error. If that
+// matters, seal something that ties the halves together, such as a hash of
+// the body or a message ID, into the header and check it after opening.
+func OpenSplit(headerBox, bodyBox []byte, headerNonce, bodyNonce nacl.Nonce, key nacl.Key) (header, body []byte, ok bool) {
+	header, ok = Open(nil, headerBox, headerNonce, key)
+	if !ok {
+		return nil, nil, false
+	}
+	body, ok = Open(nil, bodyBox, bodyNonce, key)
+	if !ok {
+		return nil, nil, false
+	}
+	return header, body, true
+}
@@ -0,0 +1,47 @@
+package secretbox
+
+import (
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestOpenSplit(t *testing.T) {
+	key := nacl.NewKey()
+	headerNonce, bodyNonce := nacl.NewNonce(), nacl.NewNonce()
+	headerBox := Seal(nil, []byte("content-type: text/plain"), headerNonce, key)
+	bodyBox := Seal(nil, []byte("hello world"), bodyNonce, key)
+
+	header, body, ok := OpenSplit(headerBox, bodyBox, headerNonce, bodyNonce, key)
+	if !ok {
+		t.Fatal("could not open split message")
+	}
+	if string(header) != "content-type: text/plain" || string(body) != "hello world" {
+		t.Errorf("got %q, %q", header, body)
+	}
+
+	corrupt := func(b []byte) []byte {
+		c := append([]byte{}, b...)
+		c[len(c)-1] ^= 1
+		return c
+	}
+	tests := []struct {
+		name                   string
+		headerBox, bodyBox     []byte
+		headerNonce, bodyNonce nacl.Nonce
+		key                    nacl.Key
+	}{
+		{"corrupt header", corrupt(headerBox), bodyBox, headerNonce, bodyNonce, key},
+		{"corrupt body", headerBox, corrupt(bodyBox), headerNonce, bodyNonce, key},
+		{"short header", headerBox[:Overhead-1], bodyBox, headerNonce, bodyNonce, key},
+		{"short body", headerBox, bodyBox[:Overhead-1], headerNonce, bodyNonce, key},
+		{"swapped nonces", headerBox, bodyBox, bodyNonce, headerNonce, key},
+		{"wrong key", headerBox, bodyBox, headerNonce, bodyNonce, nacl.NewKey()},
+	}
+	for _, tt := range tests {
+		header, body, ok := OpenSplit(tt.headerBox, tt.bodyBox, tt.headerNonce, tt.bodyNonce, tt.key)
+		if ok || header != nil || body != nil {
+			t.Errorf("%s: got %q, %q, %t, want nil, nil, false", tt.name, header, body, ok)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package secretbox
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/kevinburke/nacl"
+)
+
+var errRounds = errors.New("secretbox: rounds must be positive")
+
+// workKey derives the key for a box sealed with SealWithWork: the SHA-256
+// hash of nonce and key, hashed again rounds-1 times.
+func workKey(rounds int, nonce nacl.Nonce, key nacl.Key) nacl.Key {
+	h := sha256.New()
+	h.Write(nonce[:])
+	h.Write(key[:])
+	k := new([32]byte)
+	h.Sum(k[:0])
+	for i := 1; i < rounds; i++ {
+		*k = sha256.Sum256(k[:])
+	}
+	return k
+}
+
+// SealWithWork encrypts message with a key derived from key by hashing it
+// rounds times with SHA-256, so that opening the box requires doing the same
+// work again. A random nonce is generated, mixed into the first hash and
+// prepended to the output, as with EasySeal, so the work cannot be shared
+// between boxes. The output will be Overhead+24 bytes longer than message.
+//
+// This is not a time-lock puzzle. The work is only as slow as the hardware
+// doing it: specialized hardware can hash far faster than a general purpose
+// CPU, and anyone with key can always open the box in a time proportional to
+// rounds. The sealer does the same amount of work as the opener.
+func SealWithWork(message []byte, rounds int, key nacl.Key) ([]byte, error) {
+	if rounds < 1 {
+		return nil, errRounds
+	}
+	nonce := nacl.NewNonce()
+	return Seal(nonce[:], message, nonce, workKey(rounds, nonce, key)), nil
+}
+
+// OpenWithWork authenticates and decrypts a box produced by SealWithWork,
+// redoing the rounds hashes to derive its key. rounds must match the value
+// passed to SealWithWork.
+func OpenWithWork(box []byte, rounds int, key nacl.Key) ([]byte, error) {
+	if rounds < 1 {
+		return nil, errRounds
+	}
+	if len(box) < 24 {
+		return nil, errors.New("secretbox: message too short")
+	}
+	nonce := new([24]byte)
+	copy(nonce[:], box[:24])
+	message, ok := Open(nil, box[24:], nonce, workKey(rounds, nonce, key))
+	if !ok {
+		return nil, errInvalidInput
+	}
+	return message, nil
+}
@@ -0,0 +1,65 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSealWithWork(t *testing.T) {
+	key := nacl.NewKey()
+	message := []byte("open me later")
+	for _, rounds := range []int{1, 2, 10, 1000, 100000} {
+		box, err := SealWithWork(message, rounds, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(box) != len(message)+Overhead+24 {
+			t.Errorf("rounds=%d: got %d byte box, want %d", rounds, len(box), len(message)+Overhead+24)
+		}
+		opened, err := OpenWithWork(box, rounds, key)
+		if err != nil {
+			t.Fatalf("rounds=%d: %v", rounds, err)
+		}
+		if !bytes.Equal(opened, message) {
+			t.Errorf("rounds=%d: got %q, want %q", rounds, opened, message)
+		}
+		if _, err := OpenWithWork(box, rounds+1, key); err == nil {
+			t.Errorf("rounds=%d: opened with %d rounds", rounds, rounds+1)
+		}
+		if rounds > 1 {
+			if _, err := OpenWithWork(box, rounds-1, key); err == nil {
+				t.Errorf("rounds=%d: opened with %d rounds", rounds, rounds-1)
+			}
+		}
+		if _, err := OpenWithWork(box, rounds, nacl.NewKey()); err == nil {
+			t.Errorf("rounds=%d: opened with wrong key", rounds)
+		}
+	}
+}
+
+func TestSealWithWorkKeyDependsOnNonce(t *testing.T) {
+	key := nacl.NewKey()
+	if *workKey(10, &[24]byte{1}, key) == *workKey(10, &[24]byte{2}, key) {
+		t.Error("different nonces produced the same key")
+	}
+	if *workKey(1, &[24]byte{}, key) == *key {
+		t.Error("derived key equals key")
+	}
+}
+
+func TestSealWithWorkInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	for _, rounds := range []int{0, -1} {
+		if _, err := SealWithWork(nil, rounds, key); err != errRounds {
+			t.Errorf("SealWithWork(rounds=%d): got error %v, want %v", rounds, err, errRounds)
+		}
+		if _, err := OpenWithWork(make([]byte, 40), rounds, key); err != errRounds {
+			t.Errorf("OpenWithWork(rounds=%d): got error %v, want %v", rounds, err, errRounds)
+		}
+	}
+	if _, err := OpenWithWork(make([]byte, 23), 1, key); err == nil {
+		t.Error("opened short box")
+	}
+}
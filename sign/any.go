@@ -0,0 +1,18 @@
+package sign
+
+// OpenAny verifies signedMessage, a message signed with Sign, against each of
+// the public keys in allowed. If one of them verifies, OpenAny returns the
+// message without its signature, the key that verified it and true. Otherwise
+// it returns false. This is useful when a message may be signed by any member
+// of a set of signers, for example while rotating signing keys.
+//
+// Like Verify, OpenAny panics if any of the keys in allowed are not
+// PublicKeySize bytes long.
+func OpenAny(signedMessage []byte, allowed []PublicKey) (message []byte, signer PublicKey, ok bool) {
+	for _, key := range allowed {
+		if Verify(signedMessage, key) {
+			return signedMessage[SignatureSize:], key, true
+		}
+	}
+	return nil, nil, false
+}
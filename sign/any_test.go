@@ -0,0 +1,53 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestOpenAny(t *testing.T) {
+	var allowed []PublicKey
+	var privates []PrivateKey
+	for i := 0; i < 3; i++ {
+		pub, priv, err := Keypair(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowed = append(allowed, pub)
+		privates = append(privates, priv)
+	}
+	message := []byte("deploy v1.2.3")
+	for i, priv := range privates {
+		got, signer, ok := OpenAny(Sign(message, priv), allowed)
+		if !ok {
+			t.Fatalf("%d: could not open message signed by allowed key", i)
+		}
+		if !bytes.Equal(got, message) {
+			t.Errorf("%d: got message %q, want %q", i, got, message)
+		}
+		if !bytes.Equal(signer, allowed[i]) {
+			t.Errorf("%d: got signer %x, want %x", i, signer, allowed[i])
+		}
+	}
+
+	_, other, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := OpenAny(Sign(message, other), allowed); ok {
+		t.Errorf("opened message signed by disallowed key")
+	}
+
+	signed := Sign(message, privates[1])
+	signed[len(signed)-1] ^= 0x01
+	if _, _, ok := OpenAny(signed, allowed); ok {
+		t.Errorf("opened tampered message")
+	}
+	if _, _, ok := OpenAny(Sign(message, privates[0]), nil); ok {
+		t.Errorf("opened message with no allowed keys")
+	}
+	if _, _, ok := OpenAny(signed[:SignatureSize-1], allowed); ok {
+		t.Errorf("opened short message")
+	}
+}
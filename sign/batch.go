@@ -0,0 +1,42 @@
+package sign
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// SignBatch signs each of messages with privateKey, and returns the signed
+// messages in the same order, in the format returned by Sign.
+//
+// Ed25519 hashes each message twice with SHA-512 while signing it, which
+// dominates the cost of signing large messages. Pure Ed25519 cannot sign a
+// hash computed in advance, so SignBatch instead signs messages concurrently,
+// using up to GOMAXPROCS goroutines.
+func SignBatch(messages [][]byte, privateKey PrivateKey) ([][]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, errors.New("sign: bad private key length")
+	}
+	signed := make([][]byte, len(messages))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(messages) {
+		workers = len(messages)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range indexes {
+				signed[j] = Sign(messages[j], privateKey)
+			}
+		}()
+	}
+	for i := range messages {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return signed, nil
+}
@@ -0,0 +1,90 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+func TestSignBatch(t *testing.T) {
+	public, private, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make([][]byte, 100)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("message %d", i))
+	}
+	signed, err := SignBatch(messages, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed) != len(messages) {
+		t.Fatalf("got %d signed messages, want %d", len(signed), len(messages))
+	}
+	for i := range messages {
+		if !bytes.Equal(signed[i], Sign(messages[i], private)) {
+			t.Errorf("%d: batch signature does not match Sign", i)
+		}
+		if !Verify(signed[i], public) {
+			t.Errorf("%d: could not verify signed message", i)
+		}
+	}
+
+	signed, err = SignBatch(nil, private)
+	if err != nil || len(signed) != 0 {
+		t.Errorf("SignBatch(nil): got %v, %v", signed, err)
+	}
+	if _, err := SignBatch(messages, private[:32]); err == nil {
+		t.Errorf("expected error with short private key")
+	}
+}
+
+func batchMessages(n, size int) [][]byte {
+	messages := make([][]byte, n)
+	for i := range messages {
+		messages[i] = make([]byte, size)
+	}
+	return messages
+}
+
+func benchmarkSignSequential(b *testing.B, size int) {
+	_, private, _ := Keypair(zeroReader{})
+	messages := batchMessages(64, size)
+	b.SetBytes(int64(len(messages) * size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, m := range messages {
+			Sign(m, private)
+		}
+	}
+}
+
+func benchmarkSignBatch(b *testing.B, size int) {
+	_, private, _ := Keypair(zeroReader{})
+	messages := batchMessages(64, size)
+	b.SetBytes(int64(len(messages) * size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := SignBatch(messages, private); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSignSequential1K(b *testing.B) {
+	benchmarkSignSequential(b, 1024)
+}
+
+func BenchmarkSignBatch1K(b *testing.B) {
+	benchmarkSignBatch(b, 1024)
+}
+
+func BenchmarkSignSequential1M(b *testing.B) {
+	benchmarkSignSequential(b, 1<<20)
+}
+
+func BenchmarkSignBatch1M(b *testing.B) {
+	benchmarkSignBatch(b, 1<<20)
+}
@@ -0,0 +1,109 @@
+package sign
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// challengeContext is prepended to challenges before they are signed, so that
+// a server answering challenges cannot be used to sign any other message.
+const challengeContext = "nacl hidden service auth\x00"
+
+const (
+	// ChallengeTTL is how long a challenge can be answered after Challenge
+	// returns it.
+	ChallengeTTL = 5 * time.Minute
+
+	// MaxPendingChallenges is the largest number of unanswered challenges an
+	// HSAuth keeps. When it is reached, Challenge forgets the oldest one.
+	MaxPendingChallenges = 1024
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+func challengeMessage(challenge [32]byte) []byte {
+	return append([]byte(challengeContext), challenge[:]...)
+}
+
+// An HSAuth authenticates a server that is known only by its public signing
+// key, such as an onion service, by challenging it to sign a random value.
+// Each challenge can only be used once, so a recorded response cannot be
+// replayed. Challenges that are not answered within ChallengeTTL expire, and
+// at most MaxPendingChallenges are kept, so an HSAuth that issues challenges
+// to a server that never answers uses a bounded amount of memory.
+//
+// An HSAuth is safe for concurrent use by multiple goroutines.
+type HSAuth struct {
+	serverSignPub PublicKey
+
+	mu      sync.Mutex
+	pending map[[32]byte]time.Time // challenge to the time it was issued
+}
+
+// NewHiddenServiceAuth returns an HSAuth for the server with the public key
+// serverSignPub. It panics if len(serverSignPub) is not PublicKeySize.
+func NewHiddenServiceAuth(serverSignPub PublicKey) *HSAuth {
+	if len(serverSignPub) != PublicKeySize {
+		panic("sign: bad public key length")
+	}
+	return &HSAuth{
+		serverSignPub: serverSignPub,
+		pending:       make(map[[32]byte]time.Time),
+	}
+}
+
+// Challenge returns a new random challenge to send to the server, which
+// should answer it with RespondToChallenge within ChallengeTTL. If
+// MaxPendingChallenges challenges are already waiting for an answer, the
+// oldest is forgotten.
+func (a *HSAuth) Challenge() (challenge [32]byte) {
+	if _, err := io.ReadFull(rand.Reader, challenge[:]); err != nil {
+		panic(err)
+	}
+	t := now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var oldest [32]byte
+	var oldestAt time.Time
+	for c, issued := range a.pending {
+		if t.Sub(issued) > ChallengeTTL {
+			delete(a.pending, c)
+			continue
+		}
+		if oldestAt.IsZero() || issued.Before(oldestAt) {
+			oldest, oldestAt = c, issued
+		}
+	}
+	if len(a.pending) >= MaxPendingChallenges {
+		delete(a.pending, oldest)
+	}
+	a.pending[challenge] = t
+	return challenge
+}
+
+// VerifyResponse reports whether response is the server's signature of
+// challenge, which must have been returned by Challenge no more than
+// ChallengeTTL ago and not yet verified or forgotten. The challenge is used up
+// whether or not the response is valid.
+func (a *HSAuth) VerifyResponse(challenge [32]byte, response []byte) bool {
+	a.mu.Lock()
+	issued, pending := a.pending[challenge]
+	delete(a.pending, challenge)
+	a.mu.Unlock()
+	if !pending || now().Sub(issued) > ChallengeTTL || len(response) != SignatureSize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(a.serverSignPub), challengeMessage(challenge), response)
+}
+
+// RespondToChallenge returns the server's response to a challenge created by
+// HSAuth.Challenge: a signature of the challenge, prefixed with a fixed
+// context string, by privateKey.
+func RespondToChallenge(challenge [32]byte, privateKey PrivateKey) []byte {
+	return ed25519.Sign(ed25519.PrivateKey(privateKey), challengeMessage(challenge))
+}
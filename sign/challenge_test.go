@@ -0,0 +1,99 @@
+package sign
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+func setNow(t time.Time) func() {
+	now = func() time.Time { return t }
+	return func() { now = time.Now }
+}
+
+func TestHiddenServiceAuth(t *testing.T) {
+	serverPub, serverPriv, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewHiddenServiceAuth(serverPub)
+	challenge := a.Challenge()
+	if challenge == a.Challenge() {
+		t.Fatal("repeated challenge")
+	}
+	response := RespondToChallenge(challenge, serverPriv)
+	if !a.VerifyResponse(challenge, response) {
+		t.Fatal("could not verify response")
+	}
+	if a.VerifyResponse(challenge, response) {
+		t.Error("verified replayed response")
+	}
+
+	_, impostorPriv, _ := Keypair(rand.Reader)
+	challenge = a.Challenge()
+	if a.VerifyResponse(challenge, RespondToChallenge(challenge, impostorPriv)) {
+		t.Error("verified response from impostor")
+	}
+
+	// A plain signature of the challenge is not a valid response.
+	challenge = a.Challenge()
+	signed := Sign(challenge[:], serverPriv)
+	if a.VerifyResponse(challenge, signed[:SignatureSize]) {
+		t.Error("verified signature without context")
+	}
+
+	// Responses to challenges that were not issued are rejected.
+	var unissued [32]byte
+	if a.VerifyResponse(unissued, RespondToChallenge(unissued, serverPriv)) {
+		t.Error("verified response to unissued challenge")
+	}
+}
+
+func TestHiddenServiceAuthExpiry(t *testing.T) {
+	serverPub, serverPriv, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewHiddenServiceAuth(serverPub)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer setNow(start)()
+	late := a.Challenge()
+	setNow(start.Add(ChallengeTTL + time.Second))
+	if a.VerifyResponse(late, RespondToChallenge(late, serverPriv)) {
+		t.Error("verified response to expired challenge")
+	}
+
+	// Unanswered challenges are dropped once they expire.
+	for i := 0; i < 10; i++ {
+		a.Challenge()
+	}
+	setNow(start.Add(3 * ChallengeTTL))
+	fresh := a.Challenge()
+	if len(a.pending) != 1 {
+		t.Errorf("got %d pending challenges, want 1", len(a.pending))
+	}
+	if !a.VerifyResponse(fresh, RespondToChallenge(fresh, serverPriv)) {
+		t.Error("could not verify response to fresh challenge")
+	}
+}
+
+func TestHiddenServiceAuthMaxPending(t *testing.T) {
+	serverPub, serverPriv, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewHiddenServiceAuth(serverPub)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer setNow(start)()
+	first := a.Challenge()
+	for i := 1; i < MaxPendingChallenges+10; i++ {
+		setNow(start.Add(time.Duration(i) * time.Millisecond))
+		a.Challenge()
+	}
+	if len(a.pending) != MaxPendingChallenges {
+		t.Errorf("got %d pending challenges, want %d", len(a.pending), MaxPendingChallenges)
+	}
+	if a.VerifyResponse(first, RespondToChallenge(first, serverPriv)) {
+		t.Error("verified response to a forgotten challenge")
+	}
+}
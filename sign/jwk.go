@@ -0,0 +1,52 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/kevinburke/nacl/internal/jwk"
+	"golang.org/x/crypto/ed25519"
+)
+
+// ExportPublicKeyJWK encodes publicKey as a JSON Web Key, as described in
+// RFC 8037: {"kty":"OKP","crv":"Ed25519","x":"<base64url public key>"}.
+func ExportPublicKeyJWK(publicKey PublicKey) ([]byte, error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, errors.New("sign: bad public key length")
+	}
+	return jwk.Marshal("Ed25519", publicKey, nil)
+}
+
+// ExportPrivateKeyJWK encodes privateKey as a JSON Web Key, as described in
+// RFC 8037. The "d" parameter holds the 32 byte seed the private key was
+// generated from, and the "x" parameter the public key.
+func ExportPrivateKeyJWK(privateKey PrivateKey) ([]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, errors.New("sign: bad private key length")
+	}
+	return jwk.Marshal("Ed25519", privateKey[32:], privateKey[:32])
+}
+
+// ImportPublicKeyJWK decodes an Ed25519 public key from a JSON Web Key.
+func ImportPublicKeyJWK(data []byte) (PublicKey, error) {
+	x, _, err := jwk.Unmarshal(data, "Ed25519", false)
+	if err != nil {
+		return nil, err
+	}
+	return PublicKey(x), nil
+}
+
+// ImportPrivateKeyJWK decodes an Ed25519 private key from a JSON Web Key. It
+// returns an error if the public key in the "x" parameter does not match the
+// private key.
+func ImportPrivateKeyJWK(data []byte) (PrivateKey, error) {
+	x, d, err := jwk.Unmarshal(data, "Ed25519", true)
+	if err != nil {
+		return nil, err
+	}
+	privateKey := ed25519.NewKeyFromSeed(d)
+	if !bytes.Equal(privateKey[32:], x) {
+		return nil, errors.New("sign: JWK public key does not match private key")
+	}
+	return PrivateKey(privateKey), nil
+}
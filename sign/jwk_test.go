@@ -0,0 +1,128 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// From RFC 8037, Appendix A.1 and A.2. The key is the one from RFC 8032,
+// Section 7.1, test 1.
+const (
+	rfc8037PrivateJWK = `{"kty":"OKP","crv":"Ed25519",
+		"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+		"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	rfc8037PublicJWK = `{"kty":"OKP","crv":"Ed25519",
+		"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	rfc8032Seed   = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	rfc8032Public = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+)
+
+func TestImportJWKVectors(t *testing.T) {
+	pub, err := ImportPublicKeyJWK([]byte(rfc8037PublicJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(pub); got != rfc8032Public {
+		t.Errorf("got public key %s, want %s", got, rfc8032Public)
+	}
+	priv, err := ImportPrivateKeyJWK([]byte(rfc8037PrivateJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(priv[:32]); got != rfc8032Seed {
+		t.Errorf("got seed %s, want %s", got, rfc8032Seed)
+	}
+	if got := hex.EncodeToString(priv.Public().(PublicKey)); got != rfc8032Public {
+		t.Errorf("got public key %s, want %s", got, rfc8032Public)
+	}
+	// A private JWK is also a valid public JWK.
+	pub2, err := ImportPublicKeyJWK([]byte(rfc8037PrivateJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Errorf("public keys do not match")
+	}
+}
+
+func TestExportJWKVectors(t *testing.T) {
+	priv, err := ImportPrivateKeyJWK([]byte(rfc8037PrivateJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportPrivateKeyJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`
+	if string(data) != want {
+		t.Errorf("got private JWK\n%s\nwant\n%s", data, want)
+	}
+	data, err = ExportPublicKeyJWK(priv.Public().(PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	if string(data) != want {
+		t.Errorf("got public JWK\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	pub, priv, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportPrivateKeyJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, err := ImportPrivateKeyJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv, priv2) {
+		t.Errorf("private key does not round trip")
+	}
+	data, err = ExportPublicKeyJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub2, err := ImportPublicKeyJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Errorf("public key does not round trip")
+	}
+}
+
+func TestImportJWKErrors(t *testing.T) {
+	tests := []struct {
+		jwk     string
+		private bool
+		err     string
+	}{
+		{`{"kty":"EC","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`, false, "unsupported key type"},
+		{`{"kty":"OKP","crv":"X25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`, false, "unsupported curve"},
+		{`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHUR"}`, false, `incorrect "x" length`},
+		{`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo="}`, false, `invalid "x" parameter`},
+		{`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`, true, `missing "d" parameter`},
+		{`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","d":"AWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`, true, "does not match"},
+		{`not json`, false, "invalid character"},
+	}
+	for _, tt := range tests {
+		var err error
+		if tt.private {
+			_, err = ImportPrivateKeyJWK([]byte(tt.jwk))
+		} else {
+			_, err = ImportPublicKeyJWK([]byte(tt.jwk))
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got error %v, want error containing %q", tt.jwk, err, tt.err)
+		}
+	}
+}
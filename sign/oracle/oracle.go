@@ -0,0 +1,302 @@
+// Package oracle signs messages on behalf of remote clients, so that a
+// signing key can be kept out of the processes that need signatures.
+//
+// Clients connect to a SigningServer over TCP and send signing requests
+// encrypted with keys derived from the box shared key between client and
+// server, which also authenticates them: the server only signs messages for
+// clients whose public keys it has authorized. Requests and responses are
+// sealed with secretbox under different derived keys, so neither can be
+// reflected back as the other, and each response is bound to the nonce of
+// the request it answers. A request is a 4 byte big-endian length, followed
+// by the client's 32 byte public key, a 24 byte random nonce and the sealed
+// message. The response is a 4 byte big-endian length, followed by a 24 byte
+// random nonce and the sealed signature. The client verifies every signature
+// with the server's signing public key before returning it. If a request is
+// invalid, or the client is not authorized, the server closes the
+// connection.
+package oracle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/box"
+	"github.com/kevinburke/nacl/scalarmult"
+	"github.com/kevinburke/nacl/secretbox"
+	"github.com/kevinburke/nacl/sign"
+	"golang.org/x/crypto/ed25519"
+)
+
+// MaxMessageSize is the largest message a SigningServer will sign.
+const MaxMessageSize = 1 << 20
+
+var (
+	errInvalidInput = errors.New("oracle: Could not decrypt invalid input")
+	errTooLong      = errors.New("oracle: message too long")
+	errRejected     = errors.New("oracle: request rejected by server")
+	errBadSignature = errors.New("oracle: server returned an invalid signature")
+)
+
+// Labels for the keys that seal each direction of the exchange.
+const (
+	requestLabel  = "nacl signing oracle request"
+	responseLabel = "nacl signing oracle response"
+)
+
+// directionKeys derives the keys that seal requests and responses from the
+// box shared key between peersPublicKey and privateKey.
+func directionKeys(peersPublicKey, privateKey nacl.Key) (requestKey, responseKey nacl.Key) {
+	shared := box.Precompute(peersPublicKey, privateKey)
+	return auth.Sum([]byte(requestLabel), shared), auth.Sum([]byte(responseLabel), shared)
+}
+
+// readFrame reads a 4 byte big-endian length and that many bytes from r. The
+// length must be at least min and at most max.
+func readFrame(r io.Reader, min, max int) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size < uint32(min) || size > uint32(max) {
+		return nil, errTooLong
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+// sealFrame returns a frame containing prefix, a random nonce and message
+// sealed with key and associated data ad, along with the nonce.
+func sealFrame(prefix, message, ad []byte, key nacl.Key) ([]byte, nacl.Nonce) {
+	nonce := nacl.NewNonce()
+	frame := make([]byte, 4, 4+len(prefix)+24+len(message)+secretbox.Overhead)
+	binary.BigEndian.PutUint32(frame, uint32(len(prefix)+24+len(message)+secretbox.Overhead))
+	frame = append(frame, prefix...)
+	frame = append(frame, nonce[:]...)
+	// Reading the associated data from a bytes.Reader cannot fail.
+	sealed, _ := secretbox.SealWithADReader(message, bytes.NewReader(ad), nonce, key)
+	return append(frame, sealed...), nonce
+}
+
+// openFrame opens a frame body containing a nonce and a message sealed with
+// key and associated data ad, and returns the message and the nonce.
+func openFrame(frame, ad []byte, key nacl.Key) ([]byte, nacl.Nonce, bool) {
+	nonce := new([24]byte)
+	copy(nonce[:], frame)
+	message, err := secretbox.OpenWithADReader(frame[24:], bytes.NewReader(ad), nonce, key)
+	if err != nil {
+		return nil, nil, false
+	}
+	return message, nonce, true
+}
+
+// A SigningServer signs messages for authorized clients.
+type SigningServer struct {
+	ln         net.Listener
+	signingKey sign.PrivateKey
+	boxPublic  nacl.Key
+	boxPrivate nacl.Key
+
+	mu      sync.Mutex
+	clients map[[32]byte]bool
+	conns   map[net.Conn]bool
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// NewSigningServer listens on the TCP address addr and serves signing
+// requests. key is the seed of the Ed25519 signing key, as passed to
+// sign.KeyPairFromSeed; the server's box key pair is derived from it, so the
+// same key always gives the same server public key. No requests are signed
+// until clients are authorized with Authorize.
+func NewSigningServer(addr string, key nacl.Key) (*SigningServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	_, signingKey := sign.KeyPairFromSeed(*key)
+	boxPrivate := auth.Sum([]byte("nacl signing oracle box key"), key)
+	s := &SigningServer{
+		ln:         ln,
+		signingKey: signingKey,
+		boxPublic:  scalarmult.Base(boxPrivate),
+		boxPrivate: boxPrivate,
+		clients:    make(map[[32]byte]bool),
+		conns:      make(map[net.Conn]bool),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *SigningServer) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// PublicKey returns the server's box public key, which clients need to
+// connect to it.
+func (s *SigningServer) PublicKey() nacl.Key {
+	return s.boxPublic
+}
+
+// SigningPublicKey returns the public key that verifies the server's
+// signatures.
+func (s *SigningServer) SigningPublicKey() sign.PublicKey {
+	return s.signingKey.Public().(sign.PublicKey)
+}
+
+// Authorize allows the client with the box public key clientPub to request
+// signatures.
+func (s *SigningServer) Authorize(clientPub nacl.Key) {
+	s.mu.Lock()
+	s.clients[*clientPub] = true
+	s.mu.Unlock()
+}
+
+// Close stops listening, closes all client connections and waits for them
+// to finish.
+func (s *SigningServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *SigningServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *SigningServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	for {
+		frame, err := readFrame(conn, 32+24+secretbox.Overhead, 32+24+MaxMessageSize+secretbox.Overhead)
+		if err != nil {
+			return
+		}
+		clientPub := new([32]byte)
+		copy(clientPub[:], frame)
+		s.mu.Lock()
+		authorized := s.clients[*clientPub]
+		s.mu.Unlock()
+		if !authorized {
+			return
+		}
+		requestKey, responseKey := directionKeys(clientPub, s.boxPrivate)
+		message, nonce, ok := openFrame(frame[32:], nil, requestKey)
+		if !ok {
+			return
+		}
+		sig := ed25519.Sign(ed25519.PrivateKey(s.signingKey), message)
+		response, _ := sealFrame(nil, sig, nonce[:], responseKey)
+		if _, err := conn.Write(response); err != nil {
+			return
+		}
+	}
+}
+
+// A SigningClient requests signatures from a SigningServer. A SigningClient
+// is safe for concurrent use; requests are sent one at a time.
+type SigningClient struct {
+	signingPub  sign.PublicKey
+	clientPub   nacl.Key
+	requestKey  nacl.Key
+	responseKey nacl.Key
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSigningClient connects to the SigningServer at the TCP address addr,
+// whose box public key is serverPub and whose signatures verify with
+// signingPub, as the client with the box key pair (clientPub, clientPriv).
+func NewSigningClient(addr string, serverPub nacl.Key, signingPub sign.PublicKey, clientPub, clientPriv nacl.Key) (*SigningClient, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	requestKey, responseKey := directionKeys(serverPub, clientPriv)
+	return &SigningClient{
+		signingPub:  signingPub,
+		clientPub:   clientPub,
+		requestKey:  requestKey,
+		responseKey: responseKey,
+		conn:        conn,
+	}, nil
+}
+
+// Sign asks the server to sign message, and returns the signature. It
+// returns an error if the signature does not verify with the server's
+// signing public key.
+func (c *SigningClient) Sign(message []byte) ([64]byte, error) {
+	var sig [64]byte
+	if len(message) > MaxMessageSize {
+		return sig, errTooLong
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	request, nonce := sealFrame(c.clientPub[:], message, nil, c.requestKey)
+	if _, err := c.conn.Write(request); err != nil {
+		return sig, err
+	}
+	frame, err := readFrame(c.conn, 24+sign.SignatureSize+secretbox.Overhead, 24+sign.SignatureSize+secretbox.Overhead)
+	if err == io.EOF {
+		return sig, errRejected
+	}
+	if err != nil {
+		return sig, err
+	}
+	opened, _, ok := openFrame(frame, nonce[:], c.responseKey)
+	if !ok {
+		return sig, errInvalidInput
+	}
+	if !ed25519.Verify(ed25519.PublicKey(c.signingPub), message, opened) {
+		return sig, errBadSignature
+	}
+	copy(sig[:], opened)
+	return sig, nil
+}
+
+// Close closes the connection to the server.
+func (c *SigningClient) Close() error {
+	return c.conn.Close()
+}
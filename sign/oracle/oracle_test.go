@@ -0,0 +1,288 @@
+package oracle
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/box"
+	"github.com/kevinburke/nacl/sign"
+)
+
+func newServer(t *testing.T) *SigningServer {
+	t.Helper()
+	s, err := NewSigningServer("127.0.0.1:0", nacl.NewKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newClient(t *testing.T, s *SigningServer, authorize bool) *SigningClient {
+	t.Helper()
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorize {
+		s.Authorize(pub)
+	}
+	c, err := NewSigningClient(s.Addr().String(), s.PublicKey(), s.SigningPublicKey(), pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSign(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	c := newClient(t, s, true)
+	defer c.Close()
+	for i := 0; i < 3; i++ {
+		message := []byte(fmt.Sprintf("release v1.%d", i))
+		sig, err := c.Sign(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed := append(sig[:], message...)
+		if !sign.Verify(signed, s.SigningPublicKey()) {
+			t.Errorf("%d: could not verify signature", i)
+		}
+	}
+	if _, err := c.Sign(make([]byte, MaxMessageSize+1)); err != errTooLong {
+		t.Errorf("expected message too long error, got %v", err)
+	}
+}
+
+func TestDeterministicKeys(t *testing.T) {
+	key := nacl.NewKey()
+	s1, err := NewSigningServer("127.0.0.1:0", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s1.Close()
+	s2, err := NewSigningServer("127.0.0.1:0", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	if *s1.PublicKey() != *s2.PublicKey() {
+		t.Error("same key gave different box public keys")
+	}
+	var seed [32]byte
+	copy(seed[:], key[:])
+	if want, _ := sign.KeyPairFromSeed(seed); string(s1.SigningPublicKey()) != string(want) {
+		t.Error("signing key is not derived from the seed")
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	c := newClient(t, s, false)
+	defer c.Close()
+	if _, err := c.Sign([]byte("hello")); err != errRejected {
+		t.Errorf("expected rejected error, got %v", err)
+	}
+
+	// A client claiming an authorized public key without its private key.
+	authorizedPub, _, _ := box.GenerateKey(rand.Reader)
+	s.Authorize(authorizedPub)
+	_, priv, _ := box.GenerateKey(rand.Reader)
+	impostor, err := NewSigningClient(s.Addr().String(), s.PublicKey(), s.SigningPublicKey(), authorizedPub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer impostor.Close()
+	if _, err := impostor.Sign([]byte("hello")); err != errRejected {
+		t.Errorf("expected rejected error, got %v", err)
+	}
+}
+
+func TestWrongServerKey(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	pub, priv, _ := box.GenerateKey(rand.Reader)
+	s.Authorize(pub)
+	otherPub, _, _ := box.GenerateKey(rand.Reader)
+	c, err := NewSigningClient(s.Addr().String(), otherPub, s.SigningPublicKey(), pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Sign([]byte("hello")); err != errRejected {
+		t.Errorf("expected rejected error, got %v", err)
+	}
+}
+
+func TestWrongSigningKey(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	pub, priv, _ := box.GenerateKey(rand.Reader)
+	s.Authorize(pub)
+	otherSigningPub, _, _ := sign.Keypair(rand.Reader)
+	c, err := NewSigningClient(s.Addr().String(), s.PublicKey(), otherSigningPub, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Sign([]byte("hello")); err != errBadSignature {
+		t.Errorf("expected bad signature error, got %v", err)
+	}
+}
+
+// fakeServer accepts one connection and calls respond with each request
+// frame, including its length, writing whatever it returns to the client.
+func fakeServer(t *testing.T, respond func(request []byte) []byte) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			frame, err := readFrame(conn, 0, 32+24+MaxMessageSize+box.Overhead)
+			if err != nil {
+				return
+			}
+			request := make([]byte, 4, 4+len(frame))
+			binary.BigEndian.PutUint32(request, uint32(len(frame)))
+			if _, err := conn.Write(respond(append(request, frame...))); err != nil {
+				return
+			}
+		}
+	}()
+	return ln
+}
+
+func TestClientRejectsReflectedRequest(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	pub, priv, _ := box.GenerateKey(rand.Reader)
+	// Send the request back without the client's public key. A 64 byte
+	// message has the size of a signature.
+	ln := fakeServer(t, func(request []byte) []byte {
+		reflected := make([]byte, 4, len(request)-32)
+		binary.BigEndian.PutUint32(reflected, uint32(len(request)-4-32))
+		return append(reflected, request[4+32:]...)
+	})
+	defer ln.Close()
+	c, err := NewSigningClient(ln.Addr().String(), s.PublicKey(), s.SigningPublicKey(), pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Sign(make([]byte, sign.SignatureSize)); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestClientRejectsReplayedResponse(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	pub, priv, _ := box.GenerateKey(rand.Reader)
+	s.Authorize(pub)
+	// Forward the first request to the real server and replay its response
+	// for every later request.
+	upstream, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	var recorded []byte
+	ln := fakeServer(t, func(request []byte) []byte {
+		if recorded == nil {
+			if _, err := upstream.Write(request); err != nil {
+				return nil
+			}
+			frame, err := readFrame(upstream, 0, 1024)
+			if err != nil {
+				return nil
+			}
+			recorded = make([]byte, 4, 4+len(frame))
+			binary.BigEndian.PutUint32(recorded, uint32(len(frame)))
+			recorded = append(recorded, frame...)
+		}
+		return recorded
+	})
+	defer ln.Close()
+	c, err := NewSigningClient(ln.Addr().String(), s.PublicKey(), s.SigningPublicKey(), pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Sign([]byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Sign([]byte("first")); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestServerRejectsReflectedResponse(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	pub, priv, _ := box.GenerateKey(rand.Reader)
+	s.Authorize(pub)
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	requestKey, _ := directionKeys(s.PublicKey(), priv)
+	request, _ := sealFrame(pub[:], []byte("hello"), nil, requestKey)
+	if _, err := conn.Write(request); err != nil {
+		t.Fatal(err)
+	}
+	frame, err := readFrame(conn, 0, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Send the signed response back as a request for a signature.
+	reflected := make([]byte, 4, 4+32+len(frame))
+	binary.BigEndian.PutUint32(reflected, uint32(32+len(frame)))
+	reflected = append(reflected, pub[:]...)
+	if _, err := conn.Write(append(reflected, frame...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFrame(conn, 0, 1024); err != io.EOF {
+		t.Errorf("expected server to close the connection, got %v", err)
+	}
+}
+
+func TestConcurrentClients(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		c := newClient(t, s, true)
+		defer c.Close()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				message := []byte(fmt.Sprintf("%d-%d", i, j))
+				sig, err := c.Sign(message)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !sign.Verify(append(sig[:], message...), s.SigningPublicKey()) {
+					t.Errorf("%s: could not verify signature", message)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
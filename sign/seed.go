@@ -0,0 +1,18 @@
+package sign
+
+import (
+	"golang.org/x/crypto/ed25519"
+)
+
+// SeedSize is the size, in bytes, of the seeds accepted by KeyPairFromSeed.
+const SeedSize = 32
+
+// KeyPairFromSeed returns the Ed25519 key pair generated from seed, as
+// described in RFC 8032, Section 5.1.5. The same seed always gives the same
+// key pair, here and in any other conforming implementation, which makes it
+// useful for known-answer tests. The seed is the first SeedSize bytes of the
+// private key.
+func KeyPairFromSeed(seed [SeedSize]byte) (publicKey PublicKey, privateKey PrivateKey) {
+	privateKey = PrivateKey(ed25519.NewKeyFromSeed(seed[:]))
+	return privateKey.Public().(PublicKey), privateKey
+}
@@ -0,0 +1,282 @@
+package sign
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+)
+
+// A TransparencyLog is an append-only log of entries, in the style of
+// Certificate Transparency (RFC 6962). The entries are the leaves of a Merkle
+// tree, computed as described in RFC 6962, Section 2.1, but using BLAKE2b-256
+// as the hash. The log signs its tree heads with Ed25519, and can prove both
+// that an entry is in the tree and that a tree extends an earlier one, so a
+// log that removes or changes entries is caught.
+//
+// An inclusion proof is the 8 byte big-endian size of the tree followed by
+// the 32 byte hashes of the audit path. A consistency proof is the 8 byte
+// big-endian sizes of the old and new trees followed by the 32 byte hashes of
+// the proof.
+//
+// A TransparencyLog is safe for concurrent use by multiple goroutines.
+type TransparencyLog struct {
+	privateKey PrivateKey
+
+	mu     sync.Mutex
+	leaves [][32]byte
+}
+
+var errLogIndex = errors.New("sign: log index out of range")
+
+// NewTransparencyLog returns an empty log that signs tree heads with the
+// Ed25519 key generated from the seed key, as by KeyPairFromSeed.
+func NewTransparencyLog(key nacl.Key) *TransparencyLog {
+	_, privateKey := KeyPairFromSeed(*key)
+	return &TransparencyLog{privateKey: privateKey}
+}
+
+// PublicKey returns the public key that verifies the log's signed tree heads.
+func (l *TransparencyLog) PublicKey() PublicKey {
+	return l.privateKey.Public().(PublicKey)
+}
+
+func hashLeaf(entry []byte) [32]byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{0})
+	h.Write(entry)
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+func hashChildren(left, right [32]byte) [32]byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{1})
+	h.Write(left[:])
+	h.Write(right[:])
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// splitPoint returns the largest power of two smaller than n, for n > 1.
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// treeHash returns the Merkle tree hash of leaves, a list of leaf hashes.
+func treeHash(leaves [][32]byte) [32]byte {
+	switch len(leaves) {
+	case 0:
+		return blake2b.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return hashChildren(treeHash(leaves[:k]), treeHash(leaves[k:]))
+}
+
+// auditPath returns the audit path for leaf m, as defined in RFC 6962,
+// Section 2.1.1.
+func auditPath(m int, leaves [][32]byte) [][32]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if m < k {
+		return append(auditPath(m, leaves[:k]), treeHash(leaves[k:]))
+	}
+	return append(auditPath(m-k, leaves[k:]), treeHash(leaves[:k]))
+}
+
+// subproof returns the consistency proof between the first m leaves and all
+// of leaves, as defined in RFC 6962, Section 2.1.2.
+func subproof(m int, leaves [][32]byte, complete bool) [][32]byte {
+	if m == len(leaves) {
+		if complete {
+			return nil
+		}
+		return [][32]byte{treeHash(leaves)}
+	}
+	k := splitPoint(len(leaves))
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), treeHash(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), treeHash(leaves[:k]))
+}
+
+func encodeProof(sizes []uint64, hashes [][32]byte) []byte {
+	proof := make([]byte, 0, 8*len(sizes)+32*len(hashes))
+	for _, size := range sizes {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], size)
+		proof = append(proof, b[:]...)
+	}
+	for _, h := range hashes {
+		proof = append(proof, h[:]...)
+	}
+	return proof
+}
+
+func decodeProof(proof []byte, nsizes int) (sizes []uint64, hashes [][32]byte, ok bool) {
+	if len(proof) < 8*nsizes || (len(proof)-8*nsizes)%32 != 0 {
+		return nil, nil, false
+	}
+	for i := 0; i < nsizes; i++ {
+		sizes = append(sizes, binary.BigEndian.Uint64(proof[8*i:]))
+	}
+	for p := proof[8*nsizes:]; len(p) > 0; p = p[32:] {
+		var h [32]byte
+		copy(h[:], p)
+		hashes = append(hashes, h)
+	}
+	return sizes, hashes, true
+}
+
+// Append adds entry to the log. It returns the entry's index, the root of the
+// new tree, and a consistency proof showing that the new tree extends the
+// tree as it was before the entry was added.
+func (l *TransparencyLog) Append(entry []byte) (index uint64, merkleRoot [32]byte, proof []byte, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.leaves = append(l.leaves, hashLeaf(entry))
+	n := len(l.leaves)
+	var hashes [][32]byte
+	if n > 1 {
+		hashes = subproof(n-1, l.leaves, true)
+	}
+	return uint64(n - 1), treeHash(l.leaves), encodeProof([]uint64{uint64(n - 1), uint64(n)}, hashes), nil
+}
+
+// InclusionProof returns a proof that the entry at index is in the current
+// tree.
+func (l *TransparencyLog) InclusionProof(index uint64) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index >= uint64(len(l.leaves)) {
+		return nil, errLogIndex
+	}
+	return encodeProof([]uint64{uint64(len(l.leaves))}, auditPath(int(index), l.leaves)), nil
+}
+
+// treeHeadMessage returns the message signed for a tree head.
+func treeHeadMessage(size uint64, root [32]byte) []byte {
+	msg := make([]byte, 0, 24+8+32)
+	msg = append(msg, "nacl transparency log\x00\x00\x00"...)
+	msg = append(msg, encodeProof([]uint64{size}, nil)...)
+	return append(msg, root[:]...)
+}
+
+// SignedTreeHead returns the size and root of the current tree, and the log's
+// signature over them.
+func (l *TransparencyLog) SignedTreeHead() (size uint64, root [32]byte, signature []byte) {
+	l.mu.Lock()
+	size, root = uint64(len(l.leaves)), treeHash(l.leaves)
+	l.mu.Unlock()
+	return size, root, ed25519.Sign(ed25519.PrivateKey(l.privateKey), treeHeadMessage(size, root))
+}
+
+// VerifyTreeHead reports whether signature is a valid signature of a tree
+// head with the given size and root by the log with public key publicKey.
+func VerifyTreeHead(publicKey PublicKey, size uint64, root [32]byte, signature []byte) bool {
+	if len(publicKey) != PublicKeySize || len(signature) != SignatureSize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(publicKey), treeHeadMessage(size, root), signature)
+}
+
+// Verify reports whether proof shows that entry is at index in the tree with
+// the given root. It is the same as VerifyInclusion.
+func (l *TransparencyLog) Verify(index uint64, entry []byte, root [32]byte, proof []byte) bool {
+	return VerifyInclusion(index, entry, root, proof)
+}
+
+// VerifyInclusion reports whether proof, as returned by
+// TransparencyLog.InclusionProof, shows that entry is at index in the tree
+// with the given root, following RFC 9162, Section 2.1.3.2.
+func VerifyInclusion(index uint64, entry []byte, root [32]byte, proof []byte) bool {
+	sizes, path, ok := decodeProof(proof, 1)
+	if !ok || index >= sizes[0] {
+		return false
+	}
+	fn, sn := index, sizes[0]-1
+	r := hashLeaf(entry)
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashChildren(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = hashChildren(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && r == root
+}
+
+// VerifyConsistency reports whether proof, as returned by
+// TransparencyLog.Append, shows that the tree with root newRoot extends the
+// tree with root oldRoot, following RFC 9162, Section 2.1.4.2. It returns the
+// sizes of the two trees, which are part of the proof.
+func VerifyConsistency(oldRoot, newRoot [32]byte, proof []byte) (oldSize, newSize uint64, ok bool) {
+	sizes, path, ok := decodeProof(proof, 2)
+	if !ok {
+		return 0, 0, false
+	}
+	m, n := sizes[0], sizes[1]
+	switch {
+	case m > n:
+		return 0, 0, false
+	case m == n:
+		return m, n, len(path) == 0 && oldRoot == newRoot
+	case m == 0:
+		return m, n, len(path) == 0
+	}
+	if m&(m-1) == 0 {
+		path = append([][32]byte{oldRoot}, path...)
+	}
+	if len(path) == 0 {
+		return 0, 0, false
+	}
+	fn, sn := m-1, n-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return 0, 0, false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = hashChildren(c, fr)
+			sr = hashChildren(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = hashChildren(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if fr != oldRoot || sr != newRoot || sn != 0 {
+		return 0, 0, false
+	}
+	return m, n, true
+}
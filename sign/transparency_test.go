@@ -0,0 +1,127 @@
+package sign
+
+import (
+	"fmt"
+	"testing"
+)
+
+func testLog(t *testing.T, n int) (*TransparencyLog, [][32]byte, [][]byte) {
+	t.Helper()
+	key := new([32]byte)
+	l := NewTransparencyLog(key)
+	roots := [][32]byte{treeHash(nil)}
+	var proofs [][]byte
+	for i := 0; i < n; i++ {
+		index, root, proof, err := l.Append([]byte(fmt.Sprintf("entry %d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != uint64(i) {
+			t.Fatalf("Append: got index %d, want %d", index, i)
+		}
+		roots = append(roots, root)
+		proofs = append(proofs, proof)
+	}
+	return l, roots, proofs
+}
+
+func TestTransparencyLogInclusion(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		l, roots, _ := testLog(t, n)
+		root := roots[n]
+		for i := 0; i < n; i++ {
+			proof, err := l.InclusionProof(uint64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry := []byte(fmt.Sprintf("entry %d", i))
+			if !l.Verify(uint64(i), entry, root, proof) {
+				t.Errorf("size %d: could not verify entry %d", n, i)
+			}
+			if VerifyInclusion(uint64(i), []byte("wrong entry"), root, proof) {
+				t.Errorf("size %d: verified wrong entry at %d", n, i)
+			}
+			if VerifyInclusion(uint64(i)^1, entry, root, proof) {
+				t.Errorf("size %d: verified entry %d at wrong index", n, i)
+			}
+			if n > 1 && VerifyInclusion(uint64(i), entry, roots[n-1], proof) {
+				t.Errorf("size %d: verified entry %d against old root", n, i)
+			}
+			if len(proof) > 8 {
+				proof[len(proof)-1] ^= 1
+				if VerifyInclusion(uint64(i), entry, root, proof) {
+					t.Errorf("size %d: verified entry %d with modified proof", n, i)
+				}
+			}
+		}
+		if _, err := l.InclusionProof(uint64(n)); err == nil {
+			t.Errorf("size %d: expected error for out of range index", n)
+		}
+	}
+}
+
+func TestTransparencyLogConsistency(t *testing.T) {
+	const n = 33
+	_, roots, proofs := testLog(t, n)
+	for i, proof := range proofs {
+		oldSize, newSize, ok := VerifyConsistency(roots[i], roots[i+1], proof)
+		if !ok {
+			t.Errorf("could not verify Append proof from %d to %d", i, i+1)
+		}
+		if oldSize != uint64(i) || newSize != uint64(i+1) {
+			t.Errorf("got sizes %d, %d, want %d, %d", oldSize, newSize, i, i+1)
+		}
+		if i > 0 {
+			if _, _, ok := VerifyConsistency(roots[i-1], roots[i+1], proof); ok {
+				t.Errorf("%d: verified proof with wrong old root", i)
+			}
+		}
+	}
+
+	// Check proofs between every pair of tree sizes.
+	leaves := make([][32]byte, n)
+	for i := range leaves {
+		leaves[i] = hashLeaf([]byte(fmt.Sprintf("entry %d", i)))
+	}
+	for m := 0; m <= n; m++ {
+		for k := m; k <= n; k++ {
+			var hashes [][32]byte
+			if m > 0 {
+				hashes = subproof(m, leaves[:k], true)
+			}
+			proof := encodeProof([]uint64{uint64(m), uint64(k)}, hashes)
+			if _, _, ok := VerifyConsistency(roots[m], roots[k], proof); !ok {
+				t.Errorf("could not verify consistency from %d to %d", m, k)
+			}
+			if m > 0 && k > m {
+				if _, _, ok := VerifyConsistency(roots[m], roots[k-1], proof); ok {
+					t.Errorf("verified consistency from %d to %d with wrong new root", m, k)
+				}
+			}
+		}
+	}
+	if _, _, ok := VerifyConsistency(roots[0], roots[1], []byte{1, 2, 3}); ok {
+		t.Errorf("verified malformed proof")
+	}
+}
+
+func TestTransparencyLogTreeHead(t *testing.T) {
+	l, roots, _ := testLog(t, 5)
+	size, root, sig := l.SignedTreeHead()
+	if size != 5 || root != roots[5] {
+		t.Fatalf("got tree head %d %x, want 5 %x", size, root, roots[5])
+	}
+	if !VerifyTreeHead(l.PublicKey(), size, root, sig) {
+		t.Errorf("could not verify tree head")
+	}
+	if VerifyTreeHead(l.PublicKey(), size-1, root, sig) {
+		t.Errorf("verified tree head with wrong size")
+	}
+	if VerifyTreeHead(l.PublicKey(), size, roots[4], sig) {
+		t.Errorf("verified tree head with wrong root")
+	}
+	other := NewTransparencyLog(&[32]byte{1})
+	if VerifyTreeHead(other.PublicKey(), size, root, sig) {
+		t.Errorf("verified tree head with wrong key")
+	}
+}
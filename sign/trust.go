@@ -0,0 +1,125 @@
+package sign
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// endorsementContext is prepended to a public key before it is signed by
+// Endorse, so that an endorsement cannot be mistaken for a signature of any
+// other message.
+const endorsementContext = "nacl key endorsement\x00"
+
+func endorsementMessage(publicKey PublicKey) []byte {
+	return append([]byte(endorsementContext), publicKey...)
+}
+
+var (
+	errUntrusted    = errors.New("sign: signer is not trusted")
+	errBadPublicKey = errors.New("sign: bad public key length")
+	errEndorsement  = errors.New("sign: invalid endorsement")
+)
+
+// A TrustAnchor decides which public keys are trusted to sign messages.
+type TrustAnchor interface {
+	IsTrusted(publicKey PublicKey) bool
+}
+
+// VerifyWithTrustAnchor reports whether sig is a valid signature of message by
+// signerPub. It returns an error, and false, if signerPub is not trusted by
+// anchor or is not PublicKeySize bytes long.
+func VerifyWithTrustAnchor(message, sig []byte, signerPub PublicKey, anchor TrustAnchor) (bool, error) {
+	if len(signerPub) != PublicKeySize {
+		return false, errBadPublicKey
+	}
+	if !anchor.IsTrusted(signerPub) {
+		return false, errUntrusted
+	}
+	if len(sig) != SignatureSize {
+		return false, nil
+	}
+	return ed25519.Verify(ed25519.PublicKey(signerPub), message, sig), nil
+}
+
+// A KeyRing is a TrustAnchor that trusts a fixed set of public keys. The zero
+// value is an empty KeyRing, ready to use. A KeyRing is safe for concurrent
+// use by multiple goroutines.
+type KeyRing struct {
+	mu   sync.RWMutex
+	keys map[string]bool
+}
+
+// Add adds publicKey to the set of trusted keys.
+func (r *KeyRing) Add(publicKey PublicKey) {
+	r.mu.Lock()
+	if r.keys == nil {
+		r.keys = make(map[string]bool)
+	}
+	r.keys[string(publicKey)] = true
+	r.mu.Unlock()
+}
+
+// Remove removes publicKey from the set of trusted keys.
+func (r *KeyRing) Remove(publicKey PublicKey) {
+	r.mu.Lock()
+	delete(r.keys, string(publicKey))
+	r.mu.Unlock()
+}
+
+// IsTrusted reports whether publicKey has been added to r.
+func (r *KeyRing) IsTrusted(publicKey PublicKey) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[string(publicKey)]
+}
+
+// A SignedKeyRing is a TrustAnchor that starts with a set of trusted root
+// keys, and trusts further keys only when they are endorsed by a key it
+// already trusts. It is safe for concurrent use by multiple goroutines.
+//
+// Trust is transitive and permanent: a key endorsed by an endorsed key is
+// trusted, and there is no revocation, so every trusted key can extend trust
+// to keys of its choosing.
+type SignedKeyRing struct {
+	ring KeyRing
+}
+
+// NewSignedKeyRing returns a SignedKeyRing that trusts roots.
+func NewSignedKeyRing(roots ...PublicKey) *SignedKeyRing {
+	r := new(SignedKeyRing)
+	for _, root := range roots {
+		r.ring.Add(root)
+	}
+	return r
+}
+
+// Endorse returns privateKey's signature endorsing publicKey, for use with
+// SignedKeyRing.AddKey.
+func Endorse(publicKey PublicKey, privateKey PrivateKey) []byte {
+	return ed25519.Sign(ed25519.PrivateKey(privateKey), endorsementMessage(publicKey))
+}
+
+// AddKey adds publicKey to the set of trusted keys, if endorsement is a valid
+// endorsement of it, as returned by Endorse, by signerPub, a trusted key.
+func (r *SignedKeyRing) AddKey(publicKey PublicKey, signerPub PublicKey, endorsement []byte) error {
+	if len(publicKey) != PublicKeySize {
+		return errBadPublicKey
+	}
+	ok, err := VerifyWithTrustAnchor(endorsementMessage(publicKey), endorsement, signerPub, r)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errEndorsement
+	}
+	r.ring.Add(publicKey)
+	return nil
+}
+
+// IsTrusted reports whether publicKey is a root key or has been added with
+// AddKey.
+func (r *SignedKeyRing) IsTrusted(publicKey PublicKey) bool {
+	return r.ring.IsTrusted(publicKey)
+}
@@ -0,0 +1,90 @@
+package sign
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func newKey(t *testing.T) (PublicKey, PrivateKey) {
+	t.Helper()
+	pub, priv, err := Keypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func TestVerifyWithKeyRing(t *testing.T) {
+	pub, priv := newKey(t)
+	otherPub, otherPriv := newKey(t)
+	var ring KeyRing
+	ring.Add(pub)
+
+	message := []byte("deploy v1.2.3")
+	sig := Sign(message, priv)[:SignatureSize]
+	ok, err := VerifyWithTrustAnchor(message, sig, pub, &ring)
+	if err != nil || !ok {
+		t.Fatalf("got %t, %v, want true, nil", ok, err)
+	}
+	if ok, err := VerifyWithTrustAnchor([]byte("deploy v6.6.6"), sig, pub, &ring); err != nil || ok {
+		t.Errorf("wrong message: got %t, %v, want false, nil", ok, err)
+	}
+	if ok, err := VerifyWithTrustAnchor(message, sig[:10], pub, &ring); err != nil || ok {
+		t.Errorf("short signature: got %t, %v, want false, nil", ok, err)
+	}
+	otherSig := Sign(message, otherPriv)[:SignatureSize]
+	if ok, err := VerifyWithTrustAnchor(message, otherSig, otherPub, &ring); err != errUntrusted || ok {
+		t.Errorf("untrusted signer: got %t, %v, want false, %v", ok, err, errUntrusted)
+	}
+	if _, err := VerifyWithTrustAnchor(message, sig, pub[:31], &ring); err != errBadPublicKey {
+		t.Errorf("short key: got %v, want %v", err, errBadPublicKey)
+	}
+
+	ring.Remove(pub)
+	if _, err := VerifyWithTrustAnchor(message, sig, pub, &ring); err != errUntrusted {
+		t.Errorf("removed key: got %v, want %v", err, errUntrusted)
+	}
+}
+
+func TestSignedKeyRing(t *testing.T) {
+	rootPub, rootPriv := newKey(t)
+	intermediatePub, intermediatePriv := newKey(t)
+	leafPub, leafPriv := newKey(t)
+	roguePub, roguePriv := newKey(t)
+	ring := NewSignedKeyRing(rootPub)
+
+	// The leaf cannot be added before the intermediate key is trusted.
+	if err := ring.AddKey(leafPub, intermediatePub, Endorse(leafPub, intermediatePriv)); err != errUntrusted {
+		t.Errorf("got error %v, want %v", err, errUntrusted)
+	}
+	if err := ring.AddKey(intermediatePub, rootPub, Endorse(intermediatePub, rootPriv)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.AddKey(leafPub, intermediatePub, Endorse(leafPub, intermediatePriv)); err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("hello")
+	ok, err := VerifyWithTrustAnchor(message, Sign(message, leafPriv)[:SignatureSize], leafPub, ring)
+	if err != nil || !ok {
+		t.Errorf("leaf signature: got %t, %v, want true, nil", ok, err)
+	}
+
+	// A self-endorsed key is not trusted.
+	if err := ring.AddKey(roguePub, roguePub, Endorse(roguePub, roguePriv)); err != errUntrusted {
+		t.Errorf("self-endorsed key: got %v, want %v", err, errUntrusted)
+	}
+	// An endorsement of a different key does not carry over.
+	if err := ring.AddKey(roguePub, rootPub, Endorse(leafPub, rootPriv)); err != errEndorsement {
+		t.Errorf("wrong endorsement: got %v, want %v", err, errEndorsement)
+	}
+	// A plain signature of the key is not an endorsement.
+	if err := ring.AddKey(roguePub, rootPub, Sign(roguePub, rootPriv)[:SignatureSize]); err != errEndorsement {
+		t.Errorf("plain signature: got %v, want %v", err, errEndorsement)
+	}
+	if ring.IsTrusted(roguePub) {
+		t.Error("rogue key is trusted")
+	}
+	if err := ring.AddKey(roguePub[:5], rootPub, nil); err != errBadPublicKey {
+		t.Errorf("short key: got %v, want %v", err, errBadPublicKey)
+	}
+}
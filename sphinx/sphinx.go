@@ -0,0 +1,180 @@
+// Package sphinx builds and peels fixed-size, layered ("onion") packets for
+// routing a message over multiple hops.
+//
+// The sender wraps a message in one layer of encryption per hop, starting with
+// the last hop. Each hop removes one layer with its Curve25519 private key and
+// learns only the public key of the next hop; it does not learn the message,
+// the sender, or (because every packet is exactly PacketSize bytes long) how
+// far along the route it sits.
+//
+// Each layer is made of a fresh ephemeral public key, followed by a secretbox
+// sealed under the box shared key between the ephemeral key and the hop's key.
+// When a hop peels a layer, the packet shrinks by LayerOverhead bytes; the hop
+// restores the original size by appending filler bytes derived from its shared
+// key. The sender precomputes that filler, so the packet forwarded by each hop
+// authenticates correctly at the next hop. This is the same trick used by the
+// Sphinx mix format (Danezis and Goldberg, 2009), with secretbox standing in for
+// the Sphinx MAC and stream cipher.
+package sphinx
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/box"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+const (
+	// PacketSize is the size, in bytes, of every packet produced by NewPacket
+	// and PeelLayer.
+	PacketSize = 1024
+
+	// MaxHops is the largest number of hops a packet can be routed through.
+	MaxHops = 5
+
+	// LayerOverhead is the number of bytes used by each layer: the ephemeral
+	// public key, the secretbox tag and the next hop's public key.
+	LayerOverhead = 32 + secretbox.Overhead + 32
+
+	// MaxMessageSize is the largest message that can be carried in a packet.
+	MaxMessageSize = PacketSize - LayerOverhead*MaxHops - 2
+)
+
+// headerSize is the number of bytes preceding the ciphertext in each layer.
+const headerSize = 32 + secretbox.Overhead
+
+// Each layer is sealed under a key used exactly once, so fixed nonces are safe.
+var (
+	layerNonce  = new([24]byte)
+	fillerNonce = &[24]byte{23: 1}
+)
+
+var (
+	errNoHops       = errors.New("sphinx: at least one hop is required")
+	errTooManyHops  = errors.New("sphinx: too many hops")
+	errTooLong      = errors.New("sphinx: message too long")
+	errPacketSize   = errors.New("sphinx: incorrect packet size")
+	errInvalidInput = errors.New("sphinx: Could not decrypt invalid input")
+)
+
+// keystream returns the first n bytes of the secretbox keystream for key and
+// nonce, following the Poly1305 key.
+func keystream(n int, nonce nacl.Nonce, key nacl.Key) []byte {
+	ks := secretbox.Seal(nil, make([]byte, n), nonce, key)
+	return ks[secretbox.Overhead:]
+}
+
+func filler(sharedKey nacl.Key) []byte {
+	return keystream(LayerOverhead, fillerNonce, sharedKey)
+}
+
+func xor(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// NewPacket wraps message in one layer of encryption for each of the public
+// keys in hops. The packet should be sent to hops[0]; the final hop will
+// recover message. The returned packet is always PacketSize bytes long.
+func NewPacket(message []byte, hops []nacl.Key) ([]byte, error) {
+	n := len(hops)
+	if n == 0 {
+		return nil, errNoHops
+	}
+	if n > MaxHops {
+		return nil, errTooManyHops
+	}
+	if len(message) > MaxMessageSize {
+		return nil, errTooLong
+	}
+
+	ephemeralPubs := make([]nacl.Key, n)
+	sharedKeys := make([]nacl.Key, n)
+	for i := range hops {
+		pub, priv, err := box.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		ephemeralPubs[i] = pub
+		sharedKeys[i] = box.Precompute(hops[i], priv)
+	}
+
+	const ctSize = PacketSize - headerSize
+	keystreams := make([][]byte, n)
+	for i := range sharedKeys {
+		keystreams[i] = keystream(ctSize, layerNonce, sharedKeys[i])
+	}
+
+	// Compute the tail of the innermost packet that each hop's filler will
+	// end up overwriting, so the final layer authenticates correctly.
+	var tail []byte
+	for i := 0; i < n-1; i++ {
+		k := len(tail) / LayerOverhead
+		ks := keystreams[i][32+PacketSize-LayerOverhead*(k+1):]
+		next := make([]byte, len(tail)+LayerOverhead)
+		xor(next[:len(tail)], tail, ks[:len(tail)])
+		copy(next[len(tail):], filler(sharedKeys[i]))
+		tail = next
+	}
+
+	// Build the innermost layer. The plaintext is an all-zero next hop, the
+	// length-prefixed message and random padding, except that the final
+	// bytes are chosen so the ciphertext matches the precomputed tail.
+	plaintext := make([]byte, ctSize)
+	binary.BigEndian.PutUint16(plaintext[32:34], uint16(len(message)))
+	copy(plaintext[34:], message)
+	if _, err := rand.Read(plaintext[34+len(message) : ctSize-len(tail)]); err != nil {
+		return nil, err
+	}
+	last := n - 1
+	xor(plaintext[ctSize-len(tail):], tail, keystreams[last][ctSize-len(tail):])
+	packet := make([]byte, PacketSize)
+	copy(packet, ephemeralPubs[last][:])
+	secretbox.Seal(packet[:32], plaintext, layerNonce, sharedKeys[last])
+
+	// Wrap the remaining layers from the inside out.
+	for i := n - 2; i >= 0; i-- {
+		copy(plaintext, hops[i+1][:])
+		copy(plaintext[32:], packet[:PacketSize-LayerOverhead])
+		copy(packet, ephemeralPubs[i][:])
+		secretbox.Seal(packet[:32], plaintext, layerNonce, sharedKeys[i])
+	}
+	return packet, nil
+}
+
+// PeelLayer removes one layer of encryption from packet using the hop's
+// private key. If the hop is an intermediate hop, PeelLayer returns the packet
+// to forward and the public key of the hop to forward it to. If the hop is the
+// final hop, PeelLayer returns the original message and a nil nextHop.
+func PeelLayer(packet []byte, privateKey nacl.Key) (inner []byte, nextHop nacl.Key, err error) {
+	if len(packet) != PacketSize {
+		return nil, nil, errPacketSize
+	}
+	ephemeralPub := new([32]byte)
+	copy(ephemeralPub[:], packet[:32])
+	sharedKey := box.Precompute(ephemeralPub, privateKey)
+	plaintext, ok := secretbox.Open(nil, packet[32:], layerNonce, sharedKey)
+	if !ok {
+		return nil, nil, errInvalidInput
+	}
+
+	var zero [32]byte
+	if nacl.Verify(plaintext[:32], zero[:]) {
+		length := int(binary.BigEndian.Uint16(plaintext[32:34]))
+		if length > MaxMessageSize {
+			return nil, nil, errInvalidInput
+		}
+		return plaintext[34 : 34+length], nil, nil
+	}
+
+	nextHop = new([32]byte)
+	copy(nextHop[:], plaintext[:32])
+	inner = make([]byte, 0, PacketSize)
+	inner = append(inner, plaintext[32:]...)
+	inner = append(inner, filler(sharedKey)...)
+	return inner, nextHop, nil
+}
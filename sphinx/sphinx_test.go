@@ -0,0 +1,103 @@
+package sphinx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/box"
+)
+
+func generateHops(t *testing.T, n int) (pubs, privs []nacl.Key) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		pub, priv, err := box.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+		privs = append(privs, priv)
+	}
+	return pubs, privs
+}
+
+func TestRoute(t *testing.T) {
+	message := []byte("the eagle has landed")
+	for n := 1; n <= MaxHops; n++ {
+		pubs, privs := generateHops(t, n)
+		packet, err := NewPacket(message, pubs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < n; i++ {
+			if len(packet) != PacketSize {
+				t.Fatalf("%d hops: hop %d: got packet size %d, want %d", n, i, len(packet), PacketSize)
+			}
+			inner, next, err := PeelLayer(packet, privs[i])
+			if err != nil {
+				t.Fatalf("%d hops: hop %d: %v", n, i, err)
+			}
+			if i == n-1 {
+				if next != nil {
+					t.Fatalf("%d hops: final hop got next hop %x", n, next)
+				}
+				if !bytes.Equal(inner, message) {
+					t.Errorf("%d hops: got message %q, want %q", n, inner, message)
+				}
+				break
+			}
+			if *next != *pubs[i+1] {
+				t.Fatalf("%d hops: hop %d: got next hop %x, want %x", n, i, next, pubs[i+1])
+			}
+			packet = inner
+		}
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	pubs, _ := generateHops(t, 3)
+	_, wrong := generateHops(t, 1)
+	packet, err := NewPacket([]byte("hello"), pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := PeelLayer(packet, wrong[0]); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	pubs, privs := generateHops(t, 2)
+	packet, err := NewPacket([]byte("hello"), pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, _, err := PeelLayer(packet, privs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner[PacketSize-1] ^= 0x01
+	if _, _, err := PeelLayer(inner, privs[1]); err != errInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	pubs, _ := generateHops(t, MaxHops+1)
+	if _, err := NewPacket(nil, nil); err != errNoHops {
+		t.Errorf("expected no hops error, got %v", err)
+	}
+	if _, err := NewPacket(nil, pubs); err != errTooManyHops {
+		t.Errorf("expected too many hops error, got %v", err)
+	}
+	if _, err := NewPacket(make([]byte, MaxMessageSize+1), pubs[:1]); err != errTooLong {
+		t.Errorf("expected message too long error, got %v", err)
+	}
+	if _, err := NewPacket(make([]byte, MaxMessageSize), pubs[:MaxHops]); err != nil {
+		t.Errorf("could not create packet with maximum size message: %v", err)
+	}
+	if _, _, err := PeelLayer(make([]byte, PacketSize-1), pubs[0]); err != errPacketSize {
+		t.Errorf("expected packet size error, got %v", err)
+	}
+}
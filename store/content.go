@@ -0,0 +1,55 @@
+package store
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+var errNotFound = errors.New("store: no value for key")
+
+// contentID returns the storage key for value: the hex encoded authenticator
+// of value under a key derived from master, so identical values get the same
+// storage key, but the key cannot be computed without master.
+func contentID(value []byte, master nacl.Key) string {
+	return hex.EncodeToString(auth.Sum(value, subkey("content-addressed id", master))[:])
+}
+
+// contentKey returns the key used to seal the value stored under id. Each
+// key only ever seals one value, so a fixed nonce is safe.
+func contentKey(id string, master nacl.Key) nacl.Key {
+	return subkey("content-addressed value "+id, master)
+}
+
+var contentNonce = new([24]byte)
+
+// PutContentAddressed seals value and stores it in m under a key derived from
+// its contents and master, and returns the key. Sealing is deterministic, so
+// storing the same value twice produces the same key and box, and the map
+// holds a single copy.
+//
+// Anyone who can see the map can tell when two values are equal, and anyone
+// who also holds master can check whether the map contains a given value.
+func PutContentAddressed(m map[string][]byte, value []byte, master nacl.Key) (key string, err error) {
+	key = contentID(value, master)
+	m[key] = secretbox.Seal(nil, value, contentNonce, contentKey(key, master))
+	return key, nil
+}
+
+// GetContentAddressed retrieves and decrypts the value stored in m under key
+// by PutContentAddressed, and checks that key matches its contents.
+func GetContentAddressed(m map[string][]byte, key string, master nacl.Key) ([]byte, error) {
+	box, ok := m[key]
+	if !ok {
+		return nil, errNotFound
+	}
+	value, ok := secretbox.Open(nil, box, contentNonce, contentKey(key, master))
+	if !ok || contentID(value, master) != key {
+		return nil, fmt.Errorf("store: could not open value for %q", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestContentAddressed(t *testing.T) {
+	master := nacl.NewKey()
+	m := make(map[string][]byte)
+	key1, err := PutContentAddressed(m, []byte("cached response"), master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, err := PutContentAddressed(m, []byte("cached response"), master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key1 != key2 {
+		t.Errorf("identical values got different keys %q and %q", key1, key2)
+	}
+	if len(m) != 1 {
+		t.Errorf("got %d entries for identical values, want 1", len(m))
+	}
+	key3, err := PutContentAddressed(m, []byte("another response"), master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key3 == key1 {
+		t.Error("different values got the same key")
+	}
+	if bytes.Contains(m[key1], []byte("cached response")) {
+		t.Error("value stored in plaintext")
+	}
+
+	value, err := GetContentAddressed(m, key1, master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "cached response" {
+		t.Errorf("got %q, want %q", value, "cached response")
+	}
+
+	// A different master key gives different storage keys.
+	other := make(map[string][]byte)
+	otherKey, _ := PutContentAddressed(other, []byte("cached response"), nacl.NewKey())
+	if otherKey == key1 {
+		t.Error("storage key does not depend on master key")
+	}
+}
+
+func TestContentAddressedErrors(t *testing.T) {
+	master := nacl.NewKey()
+	m := make(map[string][]byte)
+	if _, err := GetContentAddressed(m, "missing", master); err != errNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	key1, _ := PutContentAddressed(m, []byte("one"), master)
+	key2, _ := PutContentAddressed(m, []byte("two"), master)
+	if _, err := GetContentAddressed(m, key1, nacl.NewKey()); err == nil {
+		t.Error("opened value with wrong master key")
+	}
+	m[key1], m[key2] = m[key2], m[key1]
+	if _, err := GetContentAddressed(m, key1, master); err == nil {
+		t.Error("opened value stored under the wrong key")
+	}
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// IndexToken returns the index token for keyword under master: the
+// authenticator of keyword under a key derived from master. Tokens are
+// deterministic, so a server holding boxes and their tokens can find the
+// boxes tagged with a keyword when given its token, without learning the
+// keyword or being able to compute tokens itself.
+func IndexToken(keyword string, master nacl.Key) []byte {
+	return auth.Sum([]byte(keyword), subkey("searchable index token", master))[:]
+}
+
+// SealWithIndex seals message with a key derived from master, and returns the
+// box along with the index token for each of keywords, in the same order.
+//
+// Anyone who can see the tokens can tell when two messages share a keyword,
+// and how often each keyword is used; that is the price of searching without
+// a key. Use tokens only for keywords where that leak is acceptable.
+func SealWithIndex(message []byte, keywords []string, master nacl.Key) (box []byte, tokens [][]byte, err error) {
+	box = secretbox.EasySeal(message, subkey("searchable index message", master))
+	tokens = make([][]byte, len(keywords))
+	for i, keyword := range keywords {
+		tokens[i] = IndexToken(keyword, master)
+	}
+	return box, tokens, nil
+}
+
+// OpenWithIndex decrypts a box produced by SealWithIndex.
+func OpenWithIndex(box []byte, master nacl.Key) ([]byte, error) {
+	return secretbox.EasyOpen(box, subkey("searchable index message", master))
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestSealWithIndex(t *testing.T) {
+	master := nacl.NewKey()
+	box1, tokens1, err := SealWithIndex([]byte("meeting notes"), []string{"alice", "budget"}, master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box2, tokens2, err := SealWithIndex([]byte("expense report"), []string{"budget", "bob"}, master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens1) != 2 || len(tokens2) != 2 {
+		t.Fatalf("got %d and %d tokens, want 2 each", len(tokens1), len(tokens2))
+	}
+	if !bytes.Equal(tokens1[1], tokens2[0]) {
+		t.Error("same keyword gave different tokens")
+	}
+	if bytes.Equal(tokens1[0], tokens1[1]) || bytes.Equal(tokens1[0], tokens2[1]) {
+		t.Error("different keywords gave the same token")
+	}
+	if !bytes.Equal(IndexToken("budget", master), tokens1[1]) {
+		t.Error("IndexToken does not match the token from SealWithIndex")
+	}
+	if bytes.Equal(IndexToken("budget", nacl.NewKey()), tokens1[1]) {
+		t.Error("token does not depend on master key")
+	}
+
+	for _, tt := range []struct {
+		box  []byte
+		want string
+	}{
+		{box1, "meeting notes"},
+		{box2, "expense report"},
+	} {
+		got, err := OpenWithIndex(tt.box, master)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+	if _, err := OpenWithIndex(box1, nacl.NewKey()); err == nil {
+		t.Error("opened box with the wrong master key")
+	}
+}
@@ -0,0 +1,45 @@
+// Package store encrypts maps of secret values, such as the contents of a
+// secret store, under a single master key.
+//
+// Every value is sealed with its own subkey, derived from the master key and
+// the name the value is stored under. A value can only be opened under the
+// name it was sealed with, and damage to one value does not affect any other.
+package store
+
+import (
+	"fmt"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/auth"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// subkey derives the key used to seal the value stored under name.
+func subkey(name string, master nacl.Key) nacl.Key {
+	return auth.Sum([]byte(name), master)
+}
+
+// SealStore encrypts each of the values in m with a subkey derived from master
+// and the value's name, and returns a new map containing the encrypted values.
+// Each encrypted value is secretbox.Overhead+24 bytes longer than the original.
+func SealStore(m map[string][]byte, master nacl.Key) (map[string][]byte, error) {
+	sealed := make(map[string][]byte, len(m))
+	for name, value := range m {
+		sealed[name] = secretbox.EasySeal(value, subkey(name, master))
+	}
+	return sealed, nil
+}
+
+// OpenStore decrypts a map produced by SealStore. If any value fails to
+// decrypt, OpenStore returns an error naming it.
+func OpenStore(m map[string][]byte, master nacl.Key) (map[string][]byte, error) {
+	opened := make(map[string][]byte, len(m))
+	for name, box := range m {
+		value, err := secretbox.EasyOpen(box, subkey(name, master))
+		if err != nil {
+			return nil, fmt.Errorf("store: could not open value for %q: %v", name, err)
+		}
+		opened[name] = value
+	}
+	return opened, nil
+}
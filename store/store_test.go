@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+var secrets = map[string][]byte{
+	"db_password": []byte("hunter2"),
+	"api_key":     []byte("sk_test_4eC39HqLyjWDarjtT1zdp7dc"),
+	"empty":       []byte{},
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := nacl.NewKey()
+	sealed, err := SealStore(secrets, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range secrets {
+		if bytes.Contains(sealed[name], value) && len(value) > 0 {
+			t.Errorf("sealed value for %q contains plaintext", name)
+		}
+	}
+	opened, err := OpenStore(sealed, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != len(secrets) {
+		t.Fatalf("got %d values, want %d", len(opened), len(secrets))
+	}
+	for name, value := range secrets {
+		if !bytes.Equal(opened[name], value) {
+			t.Errorf("%q: got %q, want %q", name, opened[name], value)
+		}
+	}
+}
+
+func TestCorruptValue(t *testing.T) {
+	key := nacl.NewKey()
+	sealed, err := SealStore(secrets, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed["api_key"][30] ^= 0x01
+	_, err = OpenStore(sealed, key)
+	if err == nil || !strings.Contains(err.Error(), `"api_key"`) {
+		t.Fatalf("expected error naming api_key, got %v", err)
+	}
+
+	delete(sealed, "api_key")
+	opened, err := OpenStore(sealed, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range opened {
+		if !bytes.Equal(value, secrets[name]) {
+			t.Errorf("%q: got %q, want %q", name, value, secrets[name])
+		}
+	}
+}
+
+func TestSwappedValues(t *testing.T) {
+	key := nacl.NewKey()
+	sealed, err := SealStore(secrets, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed["db_password"], sealed["api_key"] = sealed["api_key"], sealed["db_password"]
+	if _, err := OpenStore(sealed, key); err == nil {
+		t.Errorf("opened value under a different name")
+	}
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// An EncryptedSyncMap is a sync.Map whose values are kept encrypted in
+// memory, so they are not exposed by a memory dump that does not include the
+// master key. Keys are stored in plaintext.
+//
+// Each value is sealed with the subkey for the key it is stored under, as in
+// SealStore, and a random nonce. A deterministic nonce would be reused every
+// time a different value is stored under the same key.
+//
+// An EncryptedSyncMap is safe for concurrent use by multiple goroutines.
+type EncryptedSyncMap struct {
+	master nacl.Key
+	m      sync.Map
+}
+
+// NewEncryptedSyncMap returns an empty EncryptedSyncMap that encrypts values
+// with subkeys derived from key.
+func NewEncryptedSyncMap(key nacl.Key) *EncryptedSyncMap {
+	return &EncryptedSyncMap{master: key}
+}
+
+// Store encrypts value and stores it under key.
+func (m *EncryptedSyncMap) Store(key string, value []byte) {
+	m.m.Store(key, secretbox.EasySeal(value, subkey(key, m.master)))
+}
+
+// Load decrypts and returns the value stored under key. ok is false if there
+// is no value for key.
+func (m *EncryptedSyncMap) Load(key string) (value []byte, ok bool) {
+	box, ok := m.m.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return m.open(key, box.([]byte))
+}
+
+func (m *EncryptedSyncMap) open(key string, box []byte) ([]byte, bool) {
+	value, err := secretbox.EasyOpen(box, subkey(key, m.master))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Delete deletes the value for key.
+func (m *EncryptedSyncMap) Delete(key string) {
+	m.m.Delete(key)
+}
+
+// Range calls f with each key and decrypted value in the map, in the same
+// way as sync.Map.Range. If f returns false, Range stops the iteration.
+func (m *EncryptedSyncMap) Range(f func(key string, value []byte) bool) {
+	m.m.Range(func(k, v interface{}) bool {
+		key := k.(string)
+		value, ok := m.open(key, v.([]byte))
+		if !ok {
+			return true
+		}
+		return f(key, value)
+	})
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+)
+
+func TestEncryptedSyncMap(t *testing.T) {
+	m := NewEncryptedSyncMap(nacl.NewKey())
+	for name, value := range secrets {
+		m.Store(name, value)
+	}
+	for name, value := range secrets {
+		got, ok := m.Load(name)
+		if !ok {
+			t.Fatalf("%q: not found", name)
+		}
+		if !bytes.Equal(got, value) {
+			t.Errorf("%q: got %q, want %q", name, got, value)
+		}
+	}
+	if _, ok := m.Load("missing"); ok {
+		t.Error("loaded missing key")
+	}
+
+	// Values must not be stored in plaintext.
+	m.m.Range(func(k, v interface{}) bool {
+		if value := secrets[k.(string)]; len(value) > 0 && bytes.Contains(v.([]byte), value) {
+			t.Errorf("%q stored in plaintext", k)
+		}
+		return true
+	})
+
+	// Storing a new value under the same key must not reuse the nonce.
+	first, _ := m.m.Load("db_password")
+	m.Store("db_password", []byte("hunter3"))
+	second, _ := m.m.Load("db_password")
+	if bytes.Equal(first.([]byte)[:24], second.([]byte)[:24]) {
+		t.Error("nonce reused for a new value")
+	}
+	if got, _ := m.Load("db_password"); string(got) != "hunter3" {
+		t.Errorf("got %q after overwrite", got)
+	}
+
+	m.Delete("db_password")
+	if _, ok := m.Load("db_password"); ok {
+		t.Error("loaded deleted key")
+	}
+	seen := make(map[string]bool)
+	m.Range(func(key string, value []byte) bool {
+		seen[key] = true
+		if !bytes.Equal(value, secrets[key]) {
+			t.Errorf("Range: %q: got %q, want %q", key, value, secrets[key])
+		}
+		return true
+	})
+	if len(seen) != len(secrets)-1 {
+		t.Errorf("Range visited %d keys, want %d", len(seen), len(secrets)-1)
+	}
+}
+
+func TestEncryptedSyncMapConcurrent(t *testing.T) {
+	m := NewEncryptedSyncMap(nacl.NewKey())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				key := fmt.Sprintf("%d-%d", i, j)
+				m.Store(key, []byte(key))
+				if got, ok := m.Load(key); !ok || string(got) != key {
+					t.Errorf("%s: got %q, %t", key, got, ok)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
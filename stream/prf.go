@@ -0,0 +1,45 @@
+package stream
+
+import (
+	"crypto/sha256"
+
+	"github.com/kevinburke/nacl"
+)
+
+// A PRF is a keyed pseudorandom function: it maps each input to 32 bytes
+// that look random to anyone who does not hold the key, and always maps the
+// same input to the same output.
+//
+// This is not a standard construction. Eval returns the first 32 bytes of
+// the XSalsa20 keystream for the key and a nonce made from the first 24
+// bytes of the SHA-256 hash of the input. It relies on XSalsa20 behaving as
+// a PRF of its nonce, and on 192 bit truncated SHA-256 hashes not colliding,
+// so two inputs get the same output after about 2^96 inputs. Where
+// interoperability matters, prefer a standard PRF such as HMAC, see package
+// auth.
+//
+// The first 32 bytes of the XSalsa20 keystream are the Poly1305 key that
+// secretbox uses for that nonce, so Eval(input) reveals the one-time
+// authentication key secretbox would use with nonce SHA-256(input)[:24].
+// Never use a PRF key to seal messages, or any key used to seal messages as a
+// PRF key.
+type PRF struct {
+	key [32]byte
+}
+
+// NewPRF returns a PRF keyed with key.
+func NewPRF(key nacl.Key) *PRF {
+	return &PRF{key: *key}
+}
+
+// Eval returns the output of the PRF for input. It is safe to call Eval from
+// multiple goroutines at once.
+func (p *PRF) Eval(input []byte) [32]byte {
+	h := sha256.Sum256(input)
+	var nonce [24]byte
+	copy(nonce[:], h[:24])
+	var out [32]byte
+	// A prng never returns an error or a short read.
+	PRNG(&p.key, &nonce).Read(out[:])
+	return out
+}
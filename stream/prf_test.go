@@ -0,0 +1,39 @@
+package stream
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/salsa20"
+)
+
+func TestPRF(t *testing.T) {
+	key := nacl.NewKey()
+	f := NewPRF(key)
+
+	a := f.Eval([]byte("input a"))
+	if again := f.Eval([]byte("input a")); again != a {
+		t.Error("PRF is not deterministic")
+	}
+	if b := f.Eval([]byte("input b")); b == a {
+		t.Error("different inputs gave the same output")
+	}
+	if other := NewPRF(nacl.NewKey()).Eval([]byte("input a")); other == a {
+		t.Error("different keys gave the same output")
+	}
+
+	// The output is the XSalsa20 keystream for the truncated hash.
+	h := sha256.Sum256([]byte("input a"))
+	var want [32]byte
+	salsa20.XORKeyStream(want[:], want[:], h[:24], key)
+	if a != want {
+		t.Errorf("got %x, want %x", a, want)
+	}
+
+	// Changing the caller's key does not change the PRF.
+	key[0] ^= 1
+	if got := f.Eval([]byte("input a")); got != a {
+		t.Error("PRF output changed after the caller's key was modified")
+	}
+}
@@ -0,0 +1,73 @@
+// Package stream exposes the XSalsa20 keystream used by secretbox, for uses
+// such as generating deterministic test data.
+package stream
+
+import (
+	"io"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+// blockSize is the size, in bytes, of a Salsa20 block.
+const blockSize = 64
+
+type prng struct {
+	subKey  [32]byte
+	counter [16]byte
+	block   [blockSize]byte
+	// buf holds the unread part of block.
+	buf []byte
+}
+
+// PRNG returns a reader that yields the XSalsa20 keystream for key and
+// nonce, the same bytes that golang.org/x/crypto/salsa20.XORKeyStream XORs
+// with a message. The same key and nonce always produce the same stream, so
+// it is useful as a fast, deterministic source of pseudorandom test data.
+//
+// The stream is the keystream secretbox encrypts with, shifted by 32 bytes.
+// Do not use a key and nonce pair that is also used to seal messages. The
+// reader never returns an error, and is not safe for concurrent use.
+func PRNG(key nacl.Key, nonce nacl.Nonce) io.Reader {
+	p := new(prng)
+	var hNonce [16]byte
+	copy(hNonce[:], nonce[:16])
+	salsa.HSalsa20(&p.subKey, &hNonce, key, &salsa.Sigma)
+	copy(p.counter[:], nonce[16:])
+	return p
+}
+
+// next fills out, whose length is a multiple of blockSize, with keystream.
+func (p *prng) next(out []byte) {
+	for i := range out {
+		out[i] = 0
+	}
+	salsa.XORKeyStream(out, out, &p.counter, &p.subKey)
+	// XORKeyStream does not update the counter, so advance it by the number
+	// of blocks generated. The block counter is the last 8 bytes, little
+	// endian.
+	n := uint64(len(out) / blockSize)
+	for i := 8; i < 16 && n > 0; i++ {
+		n += uint64(p.counter[i])
+		p.counter[i] = byte(n)
+		n >>= 8
+	}
+}
+
+func (p *prng) Read(b []byte) (int, error) {
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	b = b[n:]
+	if whole := len(b) - len(b)%blockSize; whole > 0 {
+		p.next(b[:whole])
+		n += whole
+		b = b[whole:]
+	}
+	if len(b) > 0 {
+		p.next(p.block[:])
+		m := copy(b, p.block[:])
+		p.buf = p.block[m:]
+		n += m
+	}
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package stream
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/kevinburke/nacl"
+	"golang.org/x/crypto/salsa20"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+func read(t *testing.T, r io.Reader, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPRNGMatchesXSalsa20(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	const size = 10000
+	want := make([]byte, size)
+	salsa20.XORKeyStream(want, want, nonce[:], key)
+
+	if got := read(t, PRNG(key, nonce), size); !bytes.Equal(got, want) {
+		t.Error("PRNG output does not match the XSalsa20 keystream")
+	}
+	// Reads of uneven sizes return the same stream.
+	r := PRNG(key, nonce)
+	var got []byte
+	for _, n := range []int{1, 63, 64, 65, 0, 3, 200, 127, 1000} {
+		got = append(got, read(t, r, n)...)
+	}
+	if !bytes.Equal(got, want[:len(got)]) {
+		t.Error("PRNG output for uneven reads does not match the XSalsa20 keystream")
+	}
+}
+
+func TestPRNGCounterCarry(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := new([24]byte)
+	r := PRNG(key, nonce).(*prng)
+	// Start just before the low byte of the block counter wraps.
+	r.counter[8] = 0xff
+	got := read(t, r, 3*blockSize)
+	want := make([]byte, 3*blockSize)
+	counter := [16]byte{8: 0xff}
+	salsa.XORKeyStream(want, want, &counter, &r.subKey)
+	if !bytes.Equal(got, want) {
+		t.Error("PRNG output across a counter carry does not match")
+	}
+}
+
+func TestPRNGDeterministic(t *testing.T) {
+	key := nacl.NewKey()
+	nonce := nacl.NewNonce()
+	a := read(t, PRNG(key, nonce), 1000)
+	b := read(t, PRNG(key, nonce), 1000)
+	if !bytes.Equal(a, b) {
+		t.Error("same key and nonce produced different streams")
+	}
+	other := new([24]byte)
+	*other = *nonce
+	other[23] ^= 1
+	if c := read(t, PRNG(key, other), 1000); bytes.Equal(a[:64], c[:64]) {
+		t.Error("different nonces produced the same stream")
+	}
+	if c := read(t, PRNG(nacl.NewKey(), nonce), 1000); bytes.Equal(a[:64], c[:64]) {
+		t.Error("different keys produced the same stream")
+	}
+}
+
+func BenchmarkPRNG(b *testing.B) {
+	r := PRNG(nacl.NewKey(), nacl.NewNonce())
+	buf := make([]byte, 64*1024)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		r.Read(buf)
+	}
+}
@@ -0,0 +1,92 @@
+package token
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/nacl"
+)
+
+func setNow(t time.Time) func() {
+	now = func() time.Time { return t }
+	return func() { now = time.Now }
+}
+
+func TestIssueValidate(t *testing.T) {
+	issuedAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer setNow(issuedAt)()
+	s := NewSessionToken(nacl.NewKey())
+	userID := []byte("user-1234")
+	token, err := s.Issue(userID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, "v1.") || strings.Count(token, ".") != 2 {
+		t.Errorf("token %q does not have the form v1.NONCE.BOX", token)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("token %q is not unpadded base64url", token)
+	}
+
+	got, err := s.Validate(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, userID) {
+		t.Errorf("got user ID %q, want %q", got, userID)
+	}
+
+	setNow(issuedAt.Add(time.Hour))
+	if _, err := s.Validate(token); err != nil {
+		t.Errorf("token at expiry time: %v", err)
+	}
+	setNow(issuedAt.Add(time.Hour + time.Nanosecond))
+	if _, err := s.Validate(token); err != ErrExpired {
+		t.Errorf("got error %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestIssueNonPositiveTTL(t *testing.T) {
+	s := NewSessionToken(nacl.NewKey())
+	if _, err := s.Issue([]byte("u"), 0); err != errTTL {
+		t.Errorf("got error %v, want %v", err, errTTL)
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	key := nacl.NewKey()
+	s := NewSessionToken(key)
+	token, err := s.Issue([]byte("user"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewSessionToken(nacl.NewKey()).Issue([]byte("user"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	modified := []byte(parts[2])
+	modified[0] ^= 1
+	for _, bad := range []string{
+		"",
+		"v1",
+		"v1..",
+		"v2." + parts[1] + "." + parts[2],
+		parts[1] + "." + parts[2],
+		"v1." + parts[1] + "." + parts[2] + ".",
+		"v1." + parts[1][1:] + "." + parts[2],
+		"v1." + parts[1] + "." + string(modified),
+		"v1." + parts[1] + "." + parts[2] + "==",
+		other,
+	} {
+		if _, err := s.Validate(bad); err != errInvalidToken {
+			t.Errorf("Validate(%q): got error %v, want %v", bad, err, errInvalidToken)
+		}
+	}
+	// Another SessionTokener with the same key accepts the token.
+	if _, err := NewSessionToken(key).Validate(token); err != nil {
+		t.Errorf("token not valid for a tokener with the same key: %v", err)
+	}
+}